@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tabel untuk menyimpan sesi login pengguna beserta refresh token-nya
+CREATE TABLE user_sessions (
+    session_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    previous_token_hash VARCHAR(64),
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    expires_datetime TIMESTAMPTZ NOT NULL,
+    revoked_datetime TIMESTAMPTZ,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_datetime TIMESTAMPTZ,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE user_sessions IS 'Menyimpan sesi login pengguna. Setiap access token membawa session_id (claim sid) sehingga bisa dicabut dari server.';
+COMMENT ON COLUMN user_sessions.refresh_token_hash IS 'SHA-256 dari refresh token yang aktif. Token asli tidak pernah disimpan.';
+COMMENT ON COLUMN user_sessions.previous_token_hash IS 'SHA-256 dari refresh token sebelum rotasi terakhir. Dipakai untuk mendeteksi penggunaan ulang token yang dicuri.';
+COMMENT ON COLUMN user_sessions.revoked_datetime IS 'Waktu sesi dicabut (logout). NULL berarti sesi masih aktif.';
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+CREATE INDEX idx_user_sessions_previous_token_hash ON user_sessions(previous_token_hash);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON user_sessions
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS user_sessions;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tabel untuk token lupa password. Setiap token hanya bisa dipakai sekali.
+CREATE TABLE password_reset_tokens (
+    token_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_datetime TIMESTAMPTZ NOT NULL,
+    used_datetime TIMESTAMPTZ,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE password_reset_tokens IS 'Menyimpan token reset password. Token asli hanya dikirim lewat email, yang disimpan hanya hash SHA-256.';
+COMMENT ON COLUMN password_reset_tokens.used_datetime IS 'Waktu token dipakai atau dibatalkan. NULL berarti token belum dipakai.';
+
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS password_reset_tokens;
+
+-- +goose StatementEnd
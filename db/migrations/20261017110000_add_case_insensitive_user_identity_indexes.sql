@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Menyamakan format email yang sudah tersimpan (huruf kecil, tanpa spasi di ujung).
+-- Jika ada dua akun yang emailnya hanya berbeda huruf besar/kecil, migrasi ini akan gagal
+-- dan akun ganda tersebut harus digabung atau diperbaiki manual terlebih dahulu.
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+UPDATE users SET username = TRIM(username) WHERE username <> TRIM(username);
+
+-- Keunikan email dan username tidak membedakan huruf besar/kecil,
+-- sekaligus mempercepat pencarian login dengan LOWER(...).
+CREATE UNIQUE INDEX users_email_lower_key ON users (LOWER(email));
+CREATE UNIQUE INDEX users_username_lower_key ON users (LOWER(username));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS users_username_lower_key;
+DROP INDEX IF EXISTS users_email_lower_key;
+
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Status pengajuan menjadi penulis
+CREATE TYPE author_application_status AS ENUM (
+    'PENDING',
+    'APPROVED',
+    'REJECTED'
+);
+
+-- Tabel pengajuan menjadi penulis. Data profil penulis baru disalin ke tabel users setelah disetujui admin.
+CREATE TABLE author_applications (
+    application_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    pen_name VARCHAR(100) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    instagram VARCHAR(100) NOT NULL DEFAULT '',
+    bank_id BIGINT NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    status author_application_status NOT NULL DEFAULT 'PENDING',
+    reason TEXT NOT NULL DEFAULT '',
+    reviewer_id BIGINT,
+    reviewed_datetime TIMESTAMPTZ,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_datetime TIMESTAMPTZ,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_reviewer FOREIGN KEY(reviewer_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE author_applications IS 'Menyimpan pengajuan pengguna untuk menjadi penulis beserta hasil review admin.';
+COMMENT ON COLUMN author_applications.reason IS 'Alasan keputusan admin, wajib diisi saat pengajuan ditolak.';
+COMMENT ON COLUMN author_applications.reviewer_id IS 'ID admin yang menyetujui atau menolak pengajuan.';
+
+-- Satu pengguna hanya boleh memiliki satu pengajuan yang masih menunggu review
+CREATE UNIQUE INDEX author_applications_one_pending_per_user ON author_applications(user_id) WHERE status = 'PENDING';
+CREATE INDEX idx_author_applications_status ON author_applications(status, create_datetime);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON author_applications
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Jenis notifikasi untuk hasil review pengajuan penulis
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'AUTHOR_APPLICATION_APPROVED';
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'AUTHOR_APPLICATION_REJECTED';
+ALTER TYPE related_entity ADD VALUE IF NOT EXISTS 'AUTHOR_APPLICATION';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Nilai ENUM notification_type dan related_entity dibiarkan, PostgreSQL tidak mendukung penghapusannya.
+DROP TABLE IF EXISTS author_applications;
+DROP TYPE IF EXISTS author_application_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Role yang bisa dimiliki pengguna. Satu pengguna bisa memiliki lebih dari satu role.
+CREATE TYPE user_role AS ENUM (
+    'reader',
+    'author',
+    'moderator',
+    'admin'
+);
+
+CREATE TABLE user_roles (
+    user_id BIGINT NOT NULL,
+    role user_role NOT NULL,
+    granted_by BIGINT,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_granted_by FOREIGN KEY(granted_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE user_roles IS 'Menyimpan role setiap pengguna untuk otorisasi (reader, author, moderator, admin).';
+COMMENT ON COLUMN user_roles.granted_by IS 'ID admin yang memberikan role. NULL untuk role bawaan sistem.';
+
+CREATE INDEX idx_user_roles_role ON user_roles(role);
+
+-- Backfill: semua pengguna adalah reader, dan penulis yang sudah ada mendapat role author
+INSERT INTO user_roles (user_id, role)
+SELECT user_id, 'reader' FROM users;
+
+INSERT INTO user_roles (user_id, role)
+SELECT user_id, 'author' FROM users WHERE flg_author = 'Y';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS user_roles;
+DROP TYPE IF EXISTS user_role;
+
+-- +goose StatementEnd
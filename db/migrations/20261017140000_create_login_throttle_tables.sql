@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tabel untuk menyimpan hitungan kegagalan login per akun dan per IP (dipakai jika LOGIN_GUARD_STORE=postgres)
+CREATE TABLE login_throttle (
+    throttle_key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    blocked_until_datetime TIMESTAMPTZ
+);
+
+COMMENT ON TABLE login_throttle IS 'Hitungan kegagalan login untuk backoff dan penguncian sementara. Key berformat user:<user_id> untuk akun terdaftar, account:<identifier> untuk identifier yang tidak dikenal, 2fa:<user_id>, atau ip:<alamat>.';
+COMMENT ON COLUMN login_throttle.blocked_until_datetime IS 'Percobaan login untuk key ini ditolak sampai waktu ini. NULL berarti tidak sedang ditahan.';
+
+-- Jejak audit semua percobaan login, berhasil maupun gagal
+CREATE TABLE login_attempts (
+    attempt_id BIGSERIAL PRIMARY KEY,
+    identifier VARCHAR(320) NOT NULL,
+    user_id BIGINT,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE login_attempts IS 'Jejak audit percobaan login. user_id NULL jika identifier tidak cocok dengan akun mana pun.';
+COMMENT ON COLUMN login_attempts.failure_reason IS 'Alasan gagal: invalid_credentials, account_locked, too_many_attempts, atau email_not_verified.';
+
+CREATE INDEX idx_login_attempts_user_id ON login_attempts(user_id, create_datetime DESC);
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts(ip_address, create_datetime DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS login_throttle;
+
+-- +goose StatementEnd
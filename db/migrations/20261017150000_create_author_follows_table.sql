@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Tabel untuk mencatat pembaca yang mengikuti penulis
+CREATE TABLE author_follows (
+    follower_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, author_id),
+    CONSTRAINT fk_follower FOREIGN KEY(follower_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_author FOREIGN KEY(author_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT author_follows_not_self CHECK (follower_id <> author_id)
+);
+
+COMMENT ON TABLE author_follows IS 'Pembaca yang mengikuti penulis. Pengikut menerima notifikasi NEW_BOOK_BY_AUTHOR saat penulis menerbitkan buku baru.';
+
+-- Index untuk daftar pengikut dan fan-out notifikasi per penulis (keyset berdasarkan follower_id)
+CREATE INDEX idx_author_follows_author_id ON author_follows(author_id, follower_id);
+
+-- Menandai kapan buku pertama kali diterbitkan, agar notifikasi hanya dikirim sekali
+ALTER TABLE books ADD COLUMN first_publish_datetime TIMESTAMPTZ;
+COMMENT ON COLUMN books.first_publish_datetime IS 'Waktu buku pertama kali dipublikasikan. Publikasi ulang setelah unpublish tidak mengirim notifikasi lagi.';
+
+-- Buku yang sudah pernah terbit dianggap sudah dinotifikasikan
+UPDATE books SET first_publish_datetime = create_datetime WHERE status <> 'D';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE books DROP COLUMN IF EXISTS first_publish_datetime;
+DROP TABLE IF EXISTS author_follows;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Menambahkan bio singkat yang ditampilkan di profil publik penulis
+ALTER TABLE users ADD COLUMN bio TEXT;
+COMMENT ON COLUMN users.bio IS 'Bio singkat pengguna, ditampilkan di profil publik penulis. Maksimal 500 karakter (divalidasi aplikasi).';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS bio;
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Akun yang dihapus tidak di-DELETE, melainkan dianonimkan. Baris users tetap ada agar
+-- komentar dan ulasan pengguna tetap tampil (sebagai "Pengguna Terhapus") dan transaksi koin
+-- tetap bisa ditelusuri untuk keperluan akuntansi.
+ALTER TABLE users ADD COLUMN deleted_datetime TIMESTAMPTZ;
+COMMENT ON COLUMN users.deleted_datetime IS 'Waktu akun dihapus oleh pengguna. Data pribadi sudah dianonimkan jika kolom ini terisi.';
+
+-- Transaksi koin adalah catatan keuangan, jadi tidak boleh ikut terhapus jika baris users dihapus manual
+ALTER TABLE coin_transactions DROP CONSTRAINT fk_user;
+ALTER TABLE coin_transactions
+    ADD CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE RESTRICT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE coin_transactions DROP CONSTRAINT fk_user;
+ALTER TABLE coin_transactions
+    ADD CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_datetime;
+
+-- +goose StatementEnd
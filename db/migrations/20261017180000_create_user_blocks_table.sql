@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- MUTE: komentar dan notifikasi dari pengguna tersebut disembunyikan untuk si pemblokir.
+-- BLOCK: sama seperti MUTE, ditambah pengguna yang diblokir tidak bisa membalas komentar si pemblokir.
+CREATE TYPE user_block_type AS ENUM ('MUTE', 'BLOCK');
+
+CREATE TABLE user_blocks (
+    blocker_id BIGINT NOT NULL,
+    blocked_id BIGINT NOT NULL,
+    block_type user_block_type NOT NULL,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_datetime TIMESTAMPTZ,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT fk_blocker FOREIGN KEY(blocker_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_blocked FOREIGN KEY(blocked_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT user_blocks_not_self CHECK (blocker_id <> blocked_id)
+);
+
+COMMENT ON TABLE user_blocks IS 'Relasi blokir/bisukan antar pengguna. Satu pasangan hanya punya satu relasi; BLOCK mencakup efek MUTE.';
+COMMENT ON COLUMN user_blocks.blocker_id IS 'Pengguna yang memblokir atau membisukan.';
+COMMENT ON COLUMN user_blocks.blocked_id IS 'Pengguna yang diblokir atau dibisukan.';
+
+CREATE TRIGGER set_timestamp BEFORE UPDATE ON user_blocks FOR EACH ROW EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS user_blocks;
+DROP TYPE IF EXISTS user_block_type;
+
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Secret TOTP milik pengguna. Baris dengan confirmed_datetime NULL adalah pendaftaran yang belum dikonfirmasi.
+CREATE TABLE user_totp (
+    user_id BIGINT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_datetime TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_datetime TIMESTAMPTZ,
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE user_totp IS 'Secret TOTP (RFC 6238) untuk verifikasi dua langkah.';
+COMMENT ON COLUMN user_totp.secret IS 'Secret base32 yang dibagikan ke aplikasi authenticator.';
+COMMENT ON COLUMN user_totp.confirmed_datetime IS 'Waktu pengguna mengonfirmasi pendaftaran dengan kode pertama. 2FA hanya aktif jika kolom ini terisi.';
+COMMENT ON COLUMN user_totp.last_used_step IS 'Langkah waktu (unix/30) dari kode terakhir yang diterima, agar kode yang sama tidak bisa dipakai ulang.';
+
+CREATE TRIGGER set_timestamp BEFORE UPDATE ON user_totp FOR EACH ROW EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Kode pemulihan sekali pakai, dipakai jika perangkat authenticator hilang
+CREATE TABLE user_recovery_codes (
+    code_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_datetime TIMESTAMPTZ,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE user_recovery_codes IS 'Kode pemulihan 2FA sekali pakai. Dibuat ulang setiap kali pengguna meminta kode baru.';
+COMMENT ON COLUMN user_recovery_codes.code_hash IS 'SHA-256 dari kode pemulihan. Kode asli hanya ditampilkan sekali saat dibuat.';
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id, code_hash);
+
+-- Waktu verifikasi 2FA terakhir di sebuah sesi, untuk aksi sensitif yang butuh verifikasi ulang
+ALTER TABLE user_sessions ADD COLUMN second_factor_datetime TIMESTAMPTZ;
+COMMENT ON COLUMN user_sessions.second_factor_datetime IS 'Waktu terakhir kode 2FA diverifikasi di sesi ini. NULL jika sesi dibuat tanpa 2FA.';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS second_factor_datetime;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Informasi perangkat untuk daftar sesi aktif pengguna
+ALTER TABLE user_sessions
+    ADD COLUMN device_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN last_seen_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Sesi lama belum pernah tercatat aktivitasnya, anggap terakhir aktif saat terakhir diperbarui
+UPDATE user_sessions SET last_seen_datetime = COALESCE(update_datetime, create_datetime);
+
+COMMENT ON COLUMN user_sessions.device_name IS 'Nama perangkat dari header X-Device-Name, atau ditebak dari user agent jika header kosong.';
+COMMENT ON COLUMN user_sessions.last_seen_datetime IS 'Waktu terakhir sesi dipakai. Diperbarui paling sering sekali per SESSION_TOUCH_INTERVAL_SECONDS agar tidak menulis di setiap request.';
+COMMENT ON COLUMN user_sessions.ip_address IS 'IP terakhir yang memakai sesi, diperbarui bersama last_seen_datetime.';
+
+CREATE INDEX idx_user_sessions_active ON user_sessions(user_id, last_seen_datetime DESC) WHERE revoked_datetime IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_user_sessions_active;
+COMMENT ON COLUMN user_sessions.ip_address IS NULL;
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS last_seen_datetime,
+    DROP COLUMN IF EXISTS device_name;
+
+-- +goose StatementEnd
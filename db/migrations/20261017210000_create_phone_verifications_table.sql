@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users ADD COLUMN is_phone_verified BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN users.is_phone_verified IS 'TRUE jika nomor pada kolom phone sudah dibuktikan lewat OTP. Direset saat nomor diubah.';
+
+-- Tabel untuk menyimpan kode OTP verifikasi nomor telepon
+CREATE TABLE phone_verifications (
+    verification_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT 'sms',
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_datetime TIMESTAMPTZ NOT NULL,
+    verified_datetime TIMESTAMPTZ,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE phone_verifications IS 'Kode OTP yang dikirim untuk memverifikasi nomor telepon. Baris lama juga dipakai untuk membatasi jumlah pengiriman.';
+COMMENT ON COLUMN phone_verifications.phone IS 'Nomor yang diverifikasi. Setelah berhasil, nomor ini disimpan ke users.phone.';
+COMMENT ON COLUMN phone_verifications.code_hash IS 'SHA-256 dari kode OTP. Kode asli tidak pernah disimpan.';
+COMMENT ON COLUMN phone_verifications.attempts IS 'Jumlah percobaan kode yang salah. Kode tidak bisa dipakai lagi setelah batas tercapai.';
+
+CREATE INDEX idx_phone_verifications_user ON phone_verifications(user_id, create_datetime DESC);
+CREATE INDEX idx_phone_verifications_phone ON phone_verifications(phone, create_datetime DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS phone_verifications;
+ALTER TABLE users DROP COLUMN IF EXISTS is_phone_verified;
+
+-- +goose StatementEnd
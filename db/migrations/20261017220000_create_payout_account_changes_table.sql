@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Aturan format nomor rekening per bank. NULL berarti memakai aturan umum di aplikasi.
+ALTER TABLE banks
+    ADD COLUMN account_number_min_length SMALLINT,
+    ADD COLUMN account_number_max_length SMALLINT;
+
+COMMENT ON COLUMN banks.account_number_min_length IS 'Jumlah digit minimal nomor rekening bank ini.';
+COMMENT ON COLUMN banks.account_number_max_length IS 'Jumlah digit maksimal nomor rekening bank ini.';
+
+UPDATE banks SET account_number_min_length = rules.min_length, account_number_max_length = rules.max_length
+FROM (VALUES
+    ('014', 10, 10), -- BCA
+    ('008', 13, 13), -- Mandiri
+    ('009', 10, 10), -- BNI
+    ('002', 15, 15), -- BRI
+    ('200', 16, 16), -- BTN
+    ('022', 13, 14), -- CIMB Niaga
+    ('011', 9, 10),  -- Danamon
+    ('013', 10, 10), -- Permata
+    ('019', 10, 10), -- Panin
+    ('028', 12, 12), -- OCBC NISP
+    ('426', 15, 15), -- Mega
+    ('153', 10, 10), -- Sinarmas
+    ('023', 10, 10), -- UOB Indonesia
+    ('213', 11, 12), -- BTPN
+    ('542', 12, 12), -- Jago
+    ('567', 10, 16), -- Allo Bank
+    ('451', 10, 10)  -- BSI
+) AS rules(bank_code, min_length, max_length)
+WHERE banks.bank_code = rules.bank_code;
+
+-- Riwayat perubahan rekening pencairan penulis
+CREATE TABLE payout_account_changes (
+    change_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    old_bank_id BIGINT,
+    old_account_number VARCHAR(30),
+    new_bank_id BIGINT NOT NULL,
+    new_account_number VARCHAR(30) NOT NULL,
+    source VARCHAR(30) NOT NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    effective_datetime TIMESTAMPTZ NOT NULL,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(user_id) ON DELETE RESTRICT,
+    CONSTRAINT fk_new_bank FOREIGN KEY(new_bank_id) REFERENCES banks(bank_id)
+);
+
+COMMENT ON TABLE payout_account_changes IS 'Riwayat perubahan rekening pencairan (users.bank_id dan users.account_number). Tetap disimpan saat akun dihapus sebagai catatan keuangan.';
+COMMENT ON COLUMN payout_account_changes.source IS 'Asal perubahan: USER (endpoint rekening pencairan) atau AUTHOR_APPLICATION (persetujuan pengajuan penulis).';
+COMMENT ON COLUMN payout_account_changes.effective_datetime IS 'Pencairan baru boleh dikirim ke rekening ini setelah waktu ini (masa tunggu setelah perubahan).';
+
+CREATE INDEX idx_payout_account_changes_user ON payout_account_changes(user_id, create_datetime DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS payout_account_changes;
+ALTER TABLE banks
+    DROP COLUMN IF EXISTS account_number_max_length,
+    DROP COLUMN IF EXISTS account_number_min_length;
+
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Konfigurasi full-text search untuk judul dan deskripsi berbahasa Indonesia.
+-- Memakai kamus 'simple' (tanpa stemming dan stopword bahasa Inggris yang merusak kata Indonesia)
+-- ditambah unaccent agar "café" dan "cafe" dianggap sama.
+CREATE TEXT SEARCH CONFIGURATION nover_id (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION nover_id
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+
+-- unaccent() bawaan tidak IMMUTABLE sehingga tidak bisa dipakai di index.
+-- Pembungkus ini menyebut kamusnya secara eksplisit sehingga aman ditandai IMMUTABLE.
+CREATE FUNCTION nover_normalize(input TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT AS
+$func$ SELECT lower(public.unaccent('public.unaccent'::regdictionary, input)) $func$;
+
+COMMENT ON FUNCTION nover_normalize(TEXT) IS 'Huruf kecil tanpa aksen, dipakai untuk pencocokan trigram judul buku.';
+
+ALTER TABLE books ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('nover_id', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('nover_id', coalesce(description, '')), 'B')
+) STORED;
+
+COMMENT ON COLUMN books.search_vector IS 'Vektor full-text dari judul (bobot A) dan deskripsi (bobot B), diisi otomatis.';
+
+CREATE INDEX idx_books_search_vector ON books USING GIN (search_vector);
+CREATE INDEX idx_books_title_trgm ON books USING GIN (nover_normalize(title) gin_trgm_ops);
+CREATE INDEX idx_users_pen_name_trgm ON users USING GIN (nover_normalize(pen_name) gin_trgm_ops);
+CREATE INDEX idx_book_genres_genre_book ON book_genres(genre_id, book_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_book_genres_genre_book;
+DROP INDEX IF EXISTS idx_users_pen_name_trgm;
+DROP INDEX IF EXISTS idx_books_title_trgm;
+DROP INDEX IF EXISTS idx_books_search_vector;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS nover_normalize(TEXT);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS nover_id;
+
+-- +goose StatementEnd
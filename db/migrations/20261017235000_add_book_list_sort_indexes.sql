@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Index untuk pilihan urutan daftar buku publik (/v1/books?sort=...).
+-- Partial index karena daftar publik tidak pernah menampilkan Draft.
+CREATE INDEX idx_books_public_newest ON books(create_datetime DESC, book_id DESC) WHERE status <> 'D';
+CREATE INDEX idx_books_public_most_viewed ON books(total_views DESC, book_id DESC) WHERE status <> 'D';
+CREATE INDEX idx_books_public_top_rated ON books(rating_average DESC, total_views DESC, book_id DESC) WHERE status <> 'D';
+CREATE INDEX idx_books_public_recently_updated ON books((COALESCE(update_datetime, create_datetime)) DESC, book_id DESC) WHERE status <> 'D';
+
+-- Mendukung filter "gratis saja" yang mencari chapter terbit berbayar per buku
+CREATE INDEX idx_chapters_book_paid ON chapters(book_id) WHERE status = 'P' AND coin_cost > 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_chapters_book_paid;
+DROP INDEX IF EXISTS idx_books_public_recently_updated;
+DROP INDEX IF EXISTS idx_books_public_top_rated;
+DROP INDEX IF EXISTS idx_books_public_most_viewed;
+DROP INDEX IF EXISTS idx_books_public_newest;
+
+-- +goose StatementEnd
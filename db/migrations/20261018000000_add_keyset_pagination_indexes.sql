@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Index untuk pagination keyset (cursor) notifikasi dan riwayat transaksi.
+-- Urutannya sama persis dengan ORDER BY di DAO, termasuk ID sebagai pemutus seri,
+-- sehingga halaman berikutnya dibaca langsung dari index tanpa OFFSET.
+CREATE INDEX idx_system_notifications_user_keyset ON system_notifications(user_id, create_datetime DESC, notification_id DESC);
+CREATE INDEX idx_coin_transactions_user_keyset ON coin_transactions(user_id, create_datetime DESC, transaction_id DESC);
+
+-- Index lama hanya berisi user_id dan sudah tercakup oleh index keyset di atas
+DROP INDEX IF EXISTS idx_coin_transactions_user_id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+CREATE INDEX IF NOT EXISTS idx_coin_transactions_user_id ON coin_transactions(user_id);
+DROP INDEX IF EXISTS idx_coin_transactions_user_keyset;
+DROP INDEX IF EXISTS idx_system_notifications_user_keyset;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Riwayat perubahan metadata buku (judul, deskripsi, sampul, genre)
+CREATE TABLE book_revisions (
+    revision_id BIGSERIAL PRIMARY KEY,
+    book_id BIGINT NOT NULL,
+    editor_id BIGINT,
+    changed_fields TEXT[] NOT NULL,
+    before_data JSONB NOT NULL,
+    after_data JSONB NOT NULL,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_book FOREIGN KEY(book_id) REFERENCES books(book_id) ON DELETE CASCADE,
+    CONSTRAINT fk_editor FOREIGN KEY(editor_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE book_revisions IS 'Riwayat perubahan metadata buku, dipakai moderator untuk melihat isi buku sebelum diubah.';
+COMMENT ON COLUMN book_revisions.editor_id IS 'Pengguna yang melakukan perubahan. NULL jika akunnya sudah dihapus.';
+COMMENT ON COLUMN book_revisions.changed_fields IS 'Nama field yang berubah: title, description, coverImageUrl, genres.';
+COMMENT ON COLUMN book_revisions.before_data IS 'Snapshot lengkap metadata buku sebelum perubahan.';
+COMMENT ON COLUMN book_revisions.after_data IS 'Snapshot lengkap metadata buku setelah perubahan.';
+
+CREATE INDEX idx_book_revisions_book ON book_revisions(book_id, create_datetime DESC, revision_id DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS book_revisions;
+
+-- +goose StatementEnd
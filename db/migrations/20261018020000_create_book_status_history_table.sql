@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Riwayat perpindahan status buku (D=Draft, P=Published, C=Completed, H=On_Hold)
+CREATE TABLE book_status_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    book_id BIGINT NOT NULL,
+    from_status book_status,
+    to_status book_status NOT NULL,
+    actor_id BIGINT,
+    reason TEXT,
+    create_datetime TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_book FOREIGN KEY(book_id) REFERENCES books(book_id) ON DELETE CASCADE,
+    CONSTRAINT fk_actor FOREIGN KEY(actor_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE book_status_history IS 'Riwayat perpindahan status buku beserta pelaku dan alasannya.';
+COMMENT ON COLUMN book_status_history.from_status IS 'Status sebelumnya. NULL untuk status awal saat buku dibuat.';
+COMMENT ON COLUMN book_status_history.actor_id IS 'Pengguna yang mengubah status. NULL untuk data awal atau jika akunnya sudah dihapus.';
+
+CREATE INDEX idx_book_status_history_book ON book_status_history(book_id, create_datetime DESC, history_id DESC);
+
+-- Catat status buku yang sudah ada sebagai titik awal riwayat
+INSERT INTO book_status_history (book_id, from_status, to_status, reason, create_datetime)
+SELECT book_id, NULL, status, 'Status saat riwayat mulai dicatat', COALESCE(update_datetime, create_datetime)
+FROM books;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS book_status_history;
+
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Sebelum ada status chapter, semua chapter tersimpan sebagai draft ('D') walaupun bukunya sudah
+-- tampil ke pembaca. Chapter milik buku yang bukan draft dianggap sudah terbit agar tetap terlihat
+-- setelah tampilan publik hanya menampilkan chapter berstatus 'P'.
+-- Trigger set_timestamp dimatikan sementara agar update_datetime chapter tidak ikut berubah.
+ALTER TABLE chapters DISABLE TRIGGER set_timestamp;
+
+UPDATE chapters SET status = 'P'
+WHERE status = 'D'
+  AND book_id IN (SELECT book_id FROM books WHERE status <> 'D');
+
+ALTER TABLE chapters ENABLE TRIGGER set_timestamp;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Tidak dikembalikan: setelah migrasi ini, chapter yang terbit hasil backfill tidak bisa dibedakan
+-- dari chapter yang diterbitkan penulis.
+SELECT 1;
+
+-- +goose StatementEnd
@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"noversystem/pkg/config" // Import package config yang kita buat
+	"noversystem/pkg/dao"
 	"noversystem/pkg/routes" // Import package routes yang kita buat
 )
 
@@ -57,6 +58,16 @@ func main() {
 	defer db.Close()
 	logrus.Info("Successfully connected to the database using pgx/v5")
 
+	// Berikan role admin ke pengguna di ADMIN_USER_IDS hanya jika belum ada admin sama sekali
+	if len(cfg.Admin.UserIDs) > 0 {
+		granted, err := dao.NewUserRoleDao(db).BootstrapAdmins(context.Background(), cfg.Admin.UserIDs)
+		if err != nil {
+			logrus.Warnf("Failed to bootstrap admin role: %v", err)
+		} else if granted > 0 {
+			logrus.Infof("Granted admin role to %d user(s) from ADMIN_USER_IDS", granted)
+		}
+	}
+
 	// 4. Inisialisasi Fiber App
 	app := fiber.New(fiber.Config{
 		AppName: cfg.App.Name,
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
@@ -12,8 +14,9 @@ import (
 // Config menampung semua konfigurasi aplikasi
 type Config struct {
 	App struct {
-		Name string
-		Port string
+		Name      string
+		Port      string
+		PublicURL string // URL aplikasi frontend, dipakai untuk membuat link di email
 	}
 	DB struct {
 		DSN string // Data Source Name
@@ -21,6 +24,62 @@ type Config struct {
 	Log struct {
 		Level logrus.Level
 	}
+	Auth struct {
+		AccessTokenTTL       time.Duration // Masa berlaku access token (JWT)
+		RefreshTokenTTL      time.Duration // Masa berlaku refresh token / sesi
+		SessionTouchInterval time.Duration // Jeda minimal antar pembaruan last-seen sebuah sesi
+
+		EmailVerificationTTL time.Duration // Masa berlaku link verifikasi email
+		RequireVerifiedEmail bool          // Blokir permintaan penulis dan aksi berbayar sebelum email terverifikasi
+		PasswordResetTTL     time.Duration // Masa berlaku link reset password
+	}
+	JWT struct {
+		KeyFiles     map[string]string // kid -> path file PEM (RSA atau Ed25519)
+		SigningKeyID string            // kid yang dipakai untuk menandatangani token baru
+		LegacySecret string            // Secret HS256 lama, token lama tetap diterima selama diisi
+	}
+	LoginGuard struct {
+		Store           string        // "postgres" atau "memory"
+		MaxFailures     int           // Jumlah gagal login per akun sebelum akun dikunci sementara
+		IPMaxFailures   int           // Jumlah gagal login per IP sebelum IP dikunci sementara
+		LockoutDuration time.Duration // Lama penguncian
+		BackoffBase     time.Duration // Jeda setelah kegagalan pertama, berlipat dua setiap kegagalan berikutnya
+		BackoffMax      time.Duration // Batas atas jeda backoff
+		FailureWindow   time.Duration // Kegagalan yang lebih lama dari ini tidak dihitung lagi
+	}
+	TwoFactor struct {
+		Issuer       string        // Nama yang tampil di aplikasi authenticator
+		ChallengeTTL time.Duration // Batas waktu memasukkan kode 2FA setelah password benar
+		FreshWindow  time.Duration // Lama verifikasi 2FA dianggap masih baru untuk aksi sensitif
+	}
+	Phone struct {
+		SMSDriver       string        // Driver pengirim OTP, saat ini "console"
+		RequireVerified bool          // Wajibkan nomor telepon terverifikasi untuk pengajuan penulis
+		OTPTTL          time.Duration // Masa berlaku kode OTP
+		ResendInterval  time.Duration // Jeda minimal antar pengiriman OTP untuk pengguna yang sama
+		MaxSendsPerHour int           // Batas pengiriman OTP per jam, per pengguna dan per nomor
+		MaxAttempts     int           // Batas percobaan kode salah untuk satu OTP
+	}
+	Payout struct {
+		CoolingOff time.Duration // Masa tunggu sebelum pencairan dikirim ke rekening yang baru diganti
+	}
+	Mail struct {
+		Driver       string // "smtp" atau "log"
+		From         string
+		SMTPHost     string
+		SMTPPort     string
+		SMTPUsername string
+		SMTPPassword string
+		LogDir       string
+	}
+	Admin struct {
+		UserIDs []int64 // ID pengguna yang otomatis diberi role admin saat aplikasi dijalankan
+	}
+	Google struct {
+		ClientIDs []string // Client ID OAuth yang diterima sebagai audience ID token
+		JWKSURL   string   // URL JWKS Google
+		JWKSFile  string   // File JWKS lokal, menggantikan JWKSURL jika diisi (untuk pengujian)
+	}
 }
 
 // Cfg adalah variabel global untuk menampung konfigurasi yang sudah di-load
@@ -41,6 +100,7 @@ func LoadConfig() error {
 	if Cfg.App.Port == "" {
 		Cfg.App.Port = "8080" // Default port
 	}
+	Cfg.App.PublicURL = strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/")
 
 	// Konfigurasi Database
 	dbHost := os.Getenv("DB_HOST")
@@ -65,5 +125,110 @@ func LoadConfig() error {
 	}
 	Cfg.Log.Level = logLevel
 
+	// Konfigurasi Otentikasi
+	Cfg.Auth.AccessTokenTTL = time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute
+	Cfg.Auth.RefreshTokenTTL = time.Duration(getEnvInt("REFRESH_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour
+	Cfg.Auth.SessionTouchInterval = time.Duration(getEnvInt("SESSION_TOUCH_INTERVAL_SECONDS", 300)) * time.Second
+
+	Cfg.Auth.EmailVerificationTTL = time.Duration(getEnvInt("EMAIL_VERIFICATION_TTL_HOURS", 24)) * time.Hour
+	Cfg.Auth.RequireVerifiedEmail = os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+	Cfg.Auth.PasswordResetTTL = time.Duration(getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute
+
+	// Konfigurasi Key JWT
+	Cfg.JWT.KeyFiles = make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		kid, path = strings.TrimSpace(kid), strings.TrimSpace(path)
+		if !ok || kid == "" || path == "" {
+			return fmt.Errorf("invalid JWT_KEYS entry %q, expected kid=path", entry)
+		}
+		if _, exists := Cfg.JWT.KeyFiles[kid]; exists {
+			return fmt.Errorf("duplicate kid %q in JWT_KEYS", kid)
+		}
+		Cfg.JWT.KeyFiles[kid] = path
+	}
+	Cfg.JWT.SigningKeyID = os.Getenv("JWT_SIGNING_KID")
+	Cfg.JWT.LegacySecret = os.Getenv("JWT_SECRET_KEY")
+
+	// Konfigurasi Proteksi Brute-Force Login
+	Cfg.LoginGuard.Store = os.Getenv("LOGIN_GUARD_STORE")
+	if Cfg.LoginGuard.Store == "" {
+		Cfg.LoginGuard.Store = "memory"
+	}
+	Cfg.LoginGuard.MaxFailures = getEnvInt("LOGIN_MAX_FAILURES", 5)
+	Cfg.LoginGuard.IPMaxFailures = getEnvInt("LOGIN_IP_MAX_FAILURES", 20)
+	Cfg.LoginGuard.LockoutDuration = time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute
+	Cfg.LoginGuard.BackoffBase = time.Duration(getEnvInt("LOGIN_BACKOFF_BASE_SECONDS", 1)) * time.Second
+	Cfg.LoginGuard.BackoffMax = time.Duration(getEnvInt("LOGIN_BACKOFF_MAX_SECONDS", 60)) * time.Second
+	Cfg.LoginGuard.FailureWindow = time.Duration(getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 60)) * time.Minute
+
+	// Konfigurasi Verifikasi Dua Langkah (TOTP)
+	Cfg.TwoFactor.Issuer = os.Getenv("TOTP_ISSUER")
+	if Cfg.TwoFactor.Issuer == "" {
+		Cfg.TwoFactor.Issuer = "Nover"
+	}
+	Cfg.TwoFactor.ChallengeTTL = time.Duration(getEnvInt("TWO_FACTOR_CHALLENGE_TTL_MINUTES", 5)) * time.Minute
+	Cfg.TwoFactor.FreshWindow = time.Duration(getEnvInt("TWO_FACTOR_FRESH_MINUTES", 10)) * time.Minute
+
+	// Konfigurasi Verifikasi Nomor Telepon
+	Cfg.Phone.SMSDriver = os.Getenv("SMS_DRIVER")
+	if Cfg.Phone.SMSDriver == "" {
+		Cfg.Phone.SMSDriver = "console"
+	}
+	Cfg.Phone.RequireVerified = os.Getenv("REQUIRE_VERIFIED_PHONE") == "true"
+	Cfg.Phone.OTPTTL = time.Duration(getEnvInt("PHONE_OTP_TTL_MINUTES", 10)) * time.Minute
+	Cfg.Phone.ResendInterval = time.Duration(getEnvInt("PHONE_OTP_RESEND_SECONDS", 60)) * time.Second
+	Cfg.Phone.MaxSendsPerHour = getEnvInt("PHONE_OTP_MAX_PER_HOUR", 5)
+	Cfg.Phone.MaxAttempts = getEnvInt("PHONE_OTP_MAX_ATTEMPTS", 5)
+
+	// Konfigurasi Pencairan
+	Cfg.Payout.CoolingOff = time.Duration(getEnvInt("PAYOUT_ACCOUNT_COOLING_OFF_HOURS", 72)) * time.Hour
+
+	// Konfigurasi Email
+	Cfg.Mail.Driver = os.Getenv("MAIL_DRIVER")
+	if Cfg.Mail.Driver == "" {
+		Cfg.Mail.Driver = "log"
+	}
+	Cfg.Mail.From = os.Getenv("MAIL_FROM")
+	Cfg.Mail.SMTPHost = os.Getenv("SMTP_HOST")
+	Cfg.Mail.SMTPPort = os.Getenv("SMTP_PORT")
+	Cfg.Mail.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	Cfg.Mail.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	Cfg.Mail.LogDir = os.Getenv("MAIL_LOG_DIR")
+
+	// Konfigurasi Login Google
+	for _, clientID := range strings.Split(os.Getenv("GOOGLE_CLIENT_IDS"), ",") {
+		if clientID = strings.TrimSpace(clientID); clientID != "" {
+			Cfg.Google.ClientIDs = append(Cfg.Google.ClientIDs, clientID)
+		}
+	}
+	Cfg.Google.JWKSURL = os.Getenv("GOOGLE_JWKS_URL")
+	Cfg.Google.JWKSFile = os.Getenv("GOOGLE_JWKS_FILE")
+
+	// Konfigurasi Admin
+	for _, rawID := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if rawID = strings.TrimSpace(rawID); rawID == "" {
+			continue
+		}
+		userID, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid ADMIN_USER_IDS entry %q: %w", rawID, err)
+		}
+		Cfg.Admin.UserIDs = append(Cfg.Admin.UserIDs, userID)
+	}
+
 	return nil
+}
+
+// getEnvInt membaca environment variable bertipe angka, atau mengembalikan nilai default
+// jika variabel kosong atau tidak valid.
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
 }
\ No newline at end of file
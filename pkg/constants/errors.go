@@ -3,20 +3,63 @@ package constants
 const (
 	ErrCodeBadRequest     = "bad_request"
 	ErrCodeInternalServer = "internal_server"
+	ErrCodeInvalidCursor  = "invalid_cursor"
 
 	ErrCodeAuthInvalidCredentials   = "invalid_credentials"
 	ErrCodeAuthInputRequired        = "input_required"
 	ErrCodeAuthEmailOrUsernameTaken = "email_or_username_taken"
-	ErrCodeAuthJWTSecretMissing     = "jwt_secret_missing"
 	ErrCodeAuthTokenCreation        = "token_creation"
+	ErrCodeAuthInvalidRefreshToken  = "invalid_refresh_token"
+	ErrCodeAuthSessionRevoked       = "session_revoked"
+	ErrCodeAuthGoogleTokenInvalid   = "google_token_invalid"
+	ErrCodeAuthGoogleNotConfigured  = "google_not_configured"
+	ErrCodeAuthEmailRegisteredLocal = "email_registered_locally"
+	ErrCodeAuthInvalidToken         = "invalid_token"
+	ErrCodeAuthEmailNotVerified     = "email_not_verified"
+	ErrCodeAuthEmailAlreadyVerified = "email_already_verified"
+	ErrCodeAuthMailDeliveryFailed   = "mail_delivery_failed"
+	ErrCodeAuthWeakPassword         = "weak_password"
+	ErrCodeAuthInvalidUsername      = "invalid_username"
+	ErrCodeAuthAccountLocked        = "account_locked"
+	ErrCodeAuthTooManyAttempts      = "too_many_attempts"
+	ErrCodeAuthTwoFactorRequired    = "two_factor_required"
+	ErrCodeAuthTwoFactorInvalid     = "invalid_two_factor_code"
+	ErrCodeAuthTwoFactorEnabled     = "two_factor_already_enabled"
+	ErrCodeAuthTwoFactorNotEnabled  = "two_factor_not_enabled"
 
 	ErrCodeUserUnauthorized = "unauthorized"
 	ErrCodeUserPenNameTaken = "pen_name_taken"
 	ErrCodeUserNotFound     = "not_found"
 	ErrCodeUserUpdateFailed = "update_failed"
+	ErrCodeUserForbidden    = "forbidden"
+	ErrCodeUserInvalidRole  = "invalid_role"
+	ErrCodeUserBlocked      = "blocked"
 
-	ErrCodeBookNotOwner     = "not_owner"
-	ErrCodeBookNoChapters   = "no_chapters"
-	ErrCodeBookNotPublished = "not_published"
-	ErrCodeBookNotFound     = "not_found"
+	ErrCodePhoneNotVerified     = "phone_not_verified"
+	ErrCodePhoneAlreadyVerified = "phone_already_verified"
+	ErrCodePhoneOTPInvalid      = "invalid_otp"
+	ErrCodePhoneOTPExpired      = "otp_expired"
+	ErrCodePhoneOTPDelivery     = "otp_delivery_failed"
+
+	ErrCodeAuthorAlreadyAuthor         = "already_author"
+	ErrCodeAuthorApplicationPending    = "application_pending"
+	ErrCodeAuthorApplicationNotFound   = "application_not_found"
+	ErrCodeAuthorApplicationNotPending = "application_not_pending"
+
+	ErrCodeBankInvalid            = "invalid_bank"
+	ErrCodeBankAccountInvalid     = "invalid_account_number"
+	ErrCodePayoutAccountUnchanged = "payout_account_unchanged"
+	ErrCodePayoutAccountNotFound  = "payout_account_not_found"
+
+	ErrCodeGenreInvalid       = "invalid_genre"
+	ErrCodeGenreLimitExceeded = "genre_limit_exceeded"
+	ErrCodeGenreNotFavorite   = "genre_not_favorite"
+
+	ErrCodeBookNotOwner   = "not_owner"
+	ErrCodeBookNoChapters = "no_chapters"
+	ErrCodeBookNotFound   = "not_found"
+
+	ErrCodeBookInvalidStatusTransition = "invalid_status_transition"
+
+	ErrCodeChapterNotFound = "chapter_not_found"
 )
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountController menangani ekspor data pribadi dan penghapusan akun oleh pengguna sendiri.
+type AccountController struct {
+	accountDAO *dao.AccountDao
+	userDAO    *dao.UserDao
+	log        *logrus.Logger
+}
+
+func NewAccountController(accountDAO *dao.AccountDao, userDAO *dao.UserDao) *AccountController {
+	return &AccountController{
+		accountDAO: accountDAO,
+		userDAO:    userDAO,
+		log:        logrus.New(),
+	}
+}
+
+// DeleteAccountPayload adalah body DELETE /v1/user/me.
+// Akun dengan password wajib mengirim Password. Akun Google tanpa password mengonfirmasi dengan mengetik ulang emailnya.
+type DeleteAccountPayload struct {
+	Password     string `json:"password"`
+	ConfirmEmail string `json:"confirmEmail"`
+}
+
+// ExportMyData adalah handler untuk mengunduh semua data pribadi pengguna yang sedang login.
+// @Summary      Unduh Data Saya
+// @Description  Menghasilkan arsip zip berisi profil, komentar, ulasan, riwayat check-in, progres misi, dan transaksi koin dalam format JSON.
+// @Tags         User
+// @Produce      application/zip
+// @Security     ApiKeyAuth
+// @Success      200 {file} file "Arsip zip data pengguna"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/export [GET]
+func (c *AccountController) ExportMyData(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	export, err := c.accountDAO.ExportUserData(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengumpulkan data ekspor user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to export user data."})
+	}
+	if export == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+
+	archive, err := buildDataExportArchive(export)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal membuat arsip ekspor user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to export user data."})
+	}
+
+	fileName := fmt.Sprintf("nover-data-%d-%s.zip", userId, export.GeneratedAt.Format("20060102"))
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).Send(archive)
+}
+
+// DeleteMyAccount adalah handler untuk menghapus akun pengguna yang sedang login.
+// @Summary      Hapus Akun Saya
+// @Description  Menghapus akun secara permanen. Data profil dianonimkan, komentar dan ulasan tetap tampil sebagai "Pengguna Terhapus", riwayat transaksi koin disimpan untuk keperluan akuntansi, dan semua sesi dicabut. Akun dengan password wajib mengirim password; akun Google mengirim confirmEmail.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        confirmation body DeleteAccountPayload true "Konfirmasi penghapusan akun"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "Konfirmasi tidak dikirim"
+// @Failure      401 {object} ErrorResponse "Password atau email konfirmasi salah"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me [DELETE]
+func (c *AccountController) DeleteMyAccount(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var payload DeleteAccountPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil user ID %d untuk penghapusan akun", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve user data."})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+
+	// Token yang dicuri saja tidak cukup untuk menghapus akun, pemilik harus mengonfirmasi ulang.
+	if user.Password != "" {
+		if payload.Password == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Password is required to delete your account."})
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)) != nil {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Password is incorrect."})
+		}
+	} else {
+		if strings.TrimSpace(payload.ConfirmEmail) == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Type your email address to confirm account deletion."})
+		}
+		if !strings.EqualFold(strings.TrimSpace(payload.ConfirmEmail), user.Email) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Email confirmation does not match."})
+		}
+	}
+
+	if err := c.accountDAO.DeleteAccount(ctx.Context(), userId); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+		}
+		c.log.WithError(err).Errorf("Gagal menghapus akun user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to delete account."})
+	}
+
+	c.log.Infof("Akun user ID %d dihapus atas permintaan pengguna", userId)
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "account_deleted", "message": "Your account has been deleted."})
+}
+
+// buildDataExportArchive menulis setiap bagian data ekspor sebagai file JSON di dalam arsip zip.
+func buildDataExportArchive(export *tables.UserDataExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"comments.json", nonNilSlice(export.Comments)},
+		{"reviews.json", nonNilSlice(export.Reviews)},
+		{"checkins.json", nonNilSlice(export.Checkins)},
+		{"mission_progress.json", nonNilSlice(export.MissionProgress)},
+		{"coin_transactions.json", nonNilSlice(export.CoinTransactions)},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.GeneratedAt})
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, fmt.Errorf("gagal menulis %s: %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// nonNilSlice memastikan slice kosong ditulis sebagai [] dan bukan null.
+func nonNilSlice[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+	return items
+}
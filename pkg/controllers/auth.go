@@ -2,15 +2,25 @@ package controllers
 
 import (
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"noversystem/pkg/config"
 	"noversystem/pkg/constants"
 	"noversystem/pkg/dao"
+	"noversystem/pkg/googleauth"
+	"noversystem/pkg/jwtkeys"
+	"noversystem/pkg/loginguard"
+	"noversystem/pkg/mailer"
 	"noversystem/pkg/tables"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -20,21 +30,41 @@ type ErrorResponse struct {
 }
 
 type LoginSuccessResponse struct {
-	Token string       `json:"token"`
-	User  *tables.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refreshToken"`
+	ExpiresIn    int64        `json:"expiresIn"` // Masa berlaku access token dalam detik
+	Roles        []string     `json:"roles"`
+	User         *tables.User `json:"user,omitempty"`
 }
 
 type AuthController struct {
-	UserDao *dao.UserDao
+	UserDao          *dao.UserDao
+	SessionDao       *dao.SessionDao
+	PasswordResetDao *dao.PasswordResetDao
+	RoleDao          *dao.UserRoleDao
+	LoginAttemptDao  *dao.LoginAttemptDao
+	TwoFactorDao     *dao.TwoFactorDao
+	LoginGuard       *loginguard.Guard
+	Keys             *jwtkeys.KeySet
+	GoogleVerifier   *googleauth.Verifier
+	Mailer           mailer.Mailer
 }
 
-func NewAuthController(userDao *dao.UserDao) *AuthController {
-	return &AuthController{UserDao: userDao}
+func NewAuthController(userDao *dao.UserDao, sessionDao *dao.SessionDao, passwordResetDao *dao.PasswordResetDao, roleDao *dao.UserRoleDao, loginAttemptDao *dao.LoginAttemptDao, twoFactorDao *dao.TwoFactorDao, loginGuard *loginguard.Guard, keys *jwtkeys.KeySet, googleVerifier *googleauth.Verifier, mail mailer.Mailer) *AuthController {
+	return &AuthController{UserDao: userDao, SessionDao: sessionDao, PasswordResetDao: passwordResetDao, RoleDao: roleDao, LoginAttemptDao: loginAttemptDao, TwoFactorDao: twoFactorDao, LoginGuard: loginGuard, Keys: keys, GoogleVerifier: googleVerifier, Mailer: mail}
 }
 
+// RefreshTokenRequest adalah payload untuk memperbarui access token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LoginRequest menerima username atau email pada field identifier.
+// Field username tetap diterima untuk klien lama.
 type LoginRequest struct {
-	Username string `json:"username" example:"testuser"`
-	Password string `json:"password" example:"password123"`
+	Identifier string `json:"identifier" example:"testuser"`
+	Username   string `json:"username" example:"testuser"`
+	Password   string `json:"password" example:"password123"`
 }
 
 // Register adalah handler untuk membuat akun pengguna baru.
@@ -45,7 +75,7 @@ type LoginRequest struct {
 // @Produce json
 // @Param user body tables.User true "Informasi Registrasi Pengguna"
 // @Success 201 {object} tables.User
-// @Failure 400 {object} ErrorResponse "Input tidak valid"
+// @Failure 400 {object} ErrorResponse "Input tidak valid atau password terlalu lemah"
 // @Failure 409 {object} ErrorResponse "Email atau Username sudah terdaftar"
 // @Failure 500 {object} ErrorResponse "Error internal server"
 // @Router /auth/register [post]
@@ -55,9 +85,23 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
 	}
 
+	// Normalisasi agar akun tidak bisa digandakan hanya dengan mengubah huruf besar/kecil
+	req.Email = normalizeEmail(req.Email)
+	if req.Username != nil {
+		username := strings.TrimSpace(*req.Username)
+		req.Username = &username
+	}
+
 	if req.Email == "" || req.Password == "" || req.Username == nil || *req.Username == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Email, password, and username are required"})
 	}
+	// Username tidak boleh mengandung '@' agar identifier login selalu bisa dibedakan dari email
+	if strings.ContainsAny(*req.Username, "@ \t\n") {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidUsername, Message: "Username must not contain '@' or whitespace"})
+	}
+	if errResp := validatePassword(req.Password); errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -69,6 +113,9 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	hash := md5.Sum([]byte(uniqueString))
 	req.UserCode = hex.EncodeToString(hash[:])
 	req.LoginWith = "local"
+	// Jangan percaya nilai dari body: email baru terverifikasi lewat /auth/email/verify
+	req.IsEmailVerified = false
+	req.AvatarURL = ""
 
 	newID, err := c.UserDao.RegisterUser(ctx.Context(), &req)
 	if err != nil {
@@ -77,19 +124,27 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	req.UserId = newID
 	req.Password = ""
 
+	// Kirim email verifikasi. Kegagalan tidak membatalkan registrasi karena
+	// pengguna bisa meminta ulang lewat /auth/email/verification.
+	if err := c.sendVerificationEmail(ctx.Context(), &req); err != nil {
+		logrus.WithError(err).Warnf("Gagal mengirim email verifikasi untuk user ID %d", newID)
+	}
+
 	return ctx.Status(fiber.StatusCreated).JSON(req)
 }
 
 // Login adalah handler yang sudah diubah untuk menggunakan error codes.
 // @Summary Login pengguna
-// @Description Mengotentikasi pengguna dengan username dan password, lalu memberikan JWT.
+// @Description Mengotentikasi pengguna dengan username atau email dan password, lalu memberikan access token (JWT) berumur pendek dan refresh token. Jika pengguna mengaktifkan 2FA, response berisi challengeToken yang harus ditukar lewat /auth/login/2fa bersama kode authenticator.
 // @Tags Authentication
 // @Accept json
 // @Produce json
-// @Param login body LoginRequest true "Kredensial Login dengan Username"
+// @Param login body LoginRequest true "Kredensial Login dengan Username atau Email"
 // @Success 200 {object} LoginSuccessResponse
+// @Success 202 {object} TwoFactorChallengeResponse "Password benar, kode 2FA dibutuhkan"
 // @Failure 400 {object} ErrorResponse "Input tidak valid"
 // @Failure 401 {object} ErrorResponse "Kredensial tidak valid"
+// @Failure 429 {object} ErrorResponse "Terlalu banyak percobaan gagal, lihat header Retry-After"
 // @Failure 500 {object} ErrorResponse "Error internal server"
 // @Router /auth/login [post]
 func (c *AuthController) Login(ctx *fiber.Ctx) error {
@@ -98,43 +153,297 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
 	}
 
-	user, err := c.UserDao.FindUserByUsername(ctx.Context(), req.Username)
+	identifier := strings.TrimSpace(req.Identifier)
+	if identifier == "" {
+		identifier = strings.TrimSpace(req.Username)
+	}
+	if identifier == "" || req.Password == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Username or email and password are required"})
+	}
+
+	user, err := c.UserDao.FindUserByIdentifier(ctx.Context(), identifier)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
 	}
+
+	// Tolak lebih awal jika akun atau IP sedang ditahan, sebelum mencocokkan password dengan bcrypt
+	guardKey := loginGuardKey(identifier, user)
+	var userId *int64
+	if user != nil {
+		userId = &user.UserId
+	}
+	if decision := c.checkLoginGuard(ctx, guardKey); !decision.Allowed {
+		c.recordLoginAttempt(ctx, identifier, userId, loginFailureReason(decision))
+		return respondLoginThrottled(ctx, decision)
+	}
+
 	if user == nil {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Invalid username or password"})
+		return c.failLogin(ctx, identifier, guardKey, nil)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Invalid username or password"})
+		return c.failLogin(ctx, identifier, guardKey, userId)
+	}
+
+	user.Password = ""
+
+	twoFactorEnabled, err := c.TwoFactorDao.IsEnabled(ctx.Context(), user.UserId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
 	}
+	if twoFactorEnabled {
+		// Password benar sehingga hitungan gagal password direset, tetapi sesi baru dibuat
+		// setelah kode 2FA diverifikasi di /auth/login/2fa.
+		if err := c.LoginGuard.Succeed(ctx.Context(), guardKey); err != nil {
+			logrus.WithError(err).Error("Gagal mereset throttle login")
+		}
+		return c.respondTwoFactorChallenge(ctx, user)
+	}
+
+	c.succeedLogin(ctx, identifier, guardKey, user.UserId)
 
-	jwtSecret := os.Getenv("JWT_SECRET_KEY")
-	if jwtSecret == "" {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthJWTSecretMissing, Message: "JWT secret not configured"})
+	response, errResp := c.createSession(ctx, user, false)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(errResp)
 	}
 
+	return ctx.JSON(response)
+}
+
+// RefreshToken adalah handler untuk menukar refresh token dengan pasangan token baru.
+// @Summary Perbarui access token
+// @Description Menukar refresh token yang valid dengan access token dan refresh token baru. Refresh token lama langsung tidak berlaku (rotasi).
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param refresh body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} LoginSuccessResponse
+// @Failure 400 {object} ErrorResponse "Input tidak valid"
+// @Failure 401 {object} ErrorResponse "Refresh token tidak valid"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/refresh [post]
+func (c *AuthController) RefreshToken(ctx *fiber.Ctx) error {
+	var req RefreshTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	if req.RefreshToken == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Refresh token is required"})
+	}
+
+	newRefreshToken, newHash, err := generateRefreshToken()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"})
+	}
+
+	session, err := c.SessionDao.RotateRefreshToken(ctx.Context(), hashToken(req.RefreshToken), newHash, time.Now().Add(config.Cfg.Auth.RefreshTokenTTL), ctx.IP())
+	if err != nil {
+		if errors.Is(err, dao.ErrRefreshTokenInvalid) || errors.Is(err, dao.ErrRefreshTokenReused) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidRefreshToken, Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	user, err := c.UserDao.FindUserByID(ctx.Context(), session.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidRefreshToken, Message: "User no longer exists"})
+	}
+
+	// Role dibaca ulang setiap refresh agar perubahan role berlaku tanpa login ulang
+	roles, err := c.RoleDao.GetRolesByUserID(ctx.Context(), user.UserId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	accessToken, err := c.signAccessToken(user, session.SessionID, roles)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"})
+	}
+
+	return ctx.JSON(LoginSuccessResponse{
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(config.Cfg.Auth.AccessTokenTTL.Seconds()),
+		Roles:        roles,
+	})
+}
+
+// Logout adalah handler untuk mengakhiri sesi yang sedang dipakai.
+// @Summary Logout
+// @Description Mencabut sesi dari access token yang dipakai. Access token dan refresh token sesi ini langsung tidak berlaku.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/logout [post]
+func (c *AuthController) Logout(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	if err := c.SessionDao.RevokeSession(ctx.Context(), sessionId, userId); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to log out."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.logout.success", "message": "Logged out successfully."})
+}
+
+// LogoutAll adalah handler untuk mengakhiri semua sesi milik pengguna di semua perangkat.
+// @Summary Logout dari semua perangkat
+// @Description Mencabut seluruh sesi aktif milik pengguna, termasuk sesi yang sedang dipakai.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{code=string,message=string,revokedSessions=int}
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/logout-all [post]
+func (c *AuthController) LogoutAll(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	revoked, err := c.SessionDao.RevokeAllUserSessions(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to log out."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.logout_all.success", "message": "Logged out from all devices.", "revokedSessions": revoked})
+}
+
+// createSession membuat sesi baru untuk pengguna lalu menerbitkan access token dan refresh token.
+// secondFactorVerified diisi true jika login baru saja lolos 2FA, agar aksi sensitif tidak langsung meminta kode lagi.
+func (c *AuthController) createSession(ctx *fiber.Ctx, user *tables.User, secondFactorVerified bool) (*LoginSuccessResponse, *ErrorResponse) {
+	refreshToken, refreshHash, err := generateRefreshToken()
+	if err != nil {
+		return nil, &ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"}
+	}
+
+	session := &tables.UserSession{
+		UserID:           user.UserId,
+		RefreshTokenHash: refreshHash,
+		DeviceName:       deviceNameFromRequest(ctx),
+		UserAgent:        ctx.Get(fiber.HeaderUserAgent),
+		IPAddress:        ctx.IP(),
+		ExpiresDatetime:  time.Now().Add(config.Cfg.Auth.RefreshTokenTTL),
+	}
+	if secondFactorVerified {
+		now := time.Now()
+		session.SecondFactorDatetime = &now
+	}
+	sessionId, err := c.SessionDao.CreateSession(ctx.Context(), session)
+	if err != nil {
+		return nil, &ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to create session"}
+	}
+
+	roles, err := c.RoleDao.GetRolesByUserID(ctx.Context(), user.UserId)
+	if err != nil {
+		return nil, &ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to load user roles"}
+	}
+
+	accessToken, err := c.signAccessToken(user, sessionId, roles)
+	if err != nil {
+		return nil, &ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"}
+	}
+
+	return &LoginSuccessResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.Cfg.Auth.AccessTokenTTL.Seconds()),
+		Roles:        roles,
+		User:         user,
+	}, nil
+}
+
+// signAccessToken membuat access token berumur pendek yang terikat ke sebuah sesi (claim sid)
+// dan membawa role pengguna (claim roles) untuk otorisasi. Claim iss/aud dan header typ
+// membedakannya dari token sekali pakai yang ditandatangani key yang sama.
+func (c *AuthController) signAccessToken(user *tables.User, sessionId int64, roles []string) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
+		"iss":       jwtkeys.Issuer,
+		"aud":       jwtkeys.AccessTokenAudience,
 		"userId":    user.UserId,
 		"userCode":  user.UserCode,
 		"email":     user.Email,
 		"loginWith": user.LoginWith,
-		"exp":       time.Now().Add(time.Hour * 72).Unix(),
+		"sid":       sessionId,
+		"roles":     roles,
+		"iat":       now.Unix(),
+		"exp":       now.Add(config.Cfg.Auth.AccessTokenTTL).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"})
+	return c.Keys.Sign(claims, jwtkeys.AccessTokenType)
+}
+
+// purposeClaims adalah isi token sekali pakai (misalnya verifikasi email) yang ditandatangani server.
+// Claim purpose mencegah token untuk satu keperluan dipakai untuk keperluan lain.
+type purposeClaims struct {
+	Purpose string `json:"purpose"`
+	UserID  int64  `json:"userId"`
+	Email   string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// signPurposeToken menandatangani token untuk keperluan tertentu dengan masa berlaku ttl.
+// Claim aud khusus per keperluan memastikan token ini ditolak oleh verifier access token.
+func (c *AuthController) signPurposeToken(purpose string, userId int64, email string, ttl time.Duration) (string, error) {
+	claims := purposeClaims{
+		Purpose: purpose,
+		UserID:  userId,
+		Email:   email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtkeys.Issuer,
+			Audience:  jwt.ClaimStrings{jwtkeys.PurposeAudience(purpose)},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
+	return c.Keys.Sign(claims, "")
+}
 
-	user.Password = ""
+// parsePurposeToken memverifikasi token dan memastikan claim purpose dan aud sesuai.
+func (c *AuthController) parsePurposeToken(tokenString, purpose string) (*purposeClaims, error) {
+	claims := &purposeClaims{}
+	_, err := c.Keys.Parse(tokenString, claims,
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(jwtkeys.Issuer),
+		jwt.WithAudience(jwtkeys.PurposeAudience(purpose)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose || claims.UserID == 0 {
+		return nil, errors.New("token tidak sesuai keperluan")
+	}
+	return claims, nil
+}
 
-	response := LoginSuccessResponse{
-		Token: signedToken,
-		User:  user,
+// generateRefreshToken membuat refresh token acak beserta hash SHA-256 yang disimpan di database.
+func generateRefreshToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
 	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
 
-	return ctx.JSON(response)
+// hashToken mengembalikan hash SHA-256 (hex) dari sebuah token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail menyamakan format email sebelum disimpan atau dicari.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
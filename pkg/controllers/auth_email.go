@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/mailer"
+	"noversystem/pkg/tables"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const tokenPurposeEmailVerification = "email_verification"
+
+// VerifyEmailRequest adalah payload untuk mengonfirmasi email.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// RequestEmailVerification adalah handler untuk mengirim (ulang) email verifikasi ke pengguna yang login.
+// @Summary Kirim email verifikasi
+// @Description Mengirim link verifikasi ke alamat email pengguna yang sedang login.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 409 {object} ErrorResponse "Email sudah terverifikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/email/verification [post]
+func (c *AuthController) RequestEmailVerification(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	user, err := c.UserDao.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+	if user.IsEmailVerified {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthEmailAlreadyVerified, Message: "Email is already verified."})
+	}
+
+	if err := c.sendVerificationEmail(ctx.Context(), user); err != nil {
+		logrus.WithError(err).Errorf("Gagal mengirim email verifikasi untuk user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthMailDeliveryFailed, Message: "Failed to send verification email."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.email_verification.sent", "message": "Verification email sent."})
+}
+
+// VerifyEmail adalah handler untuk mengonfirmasi email menggunakan token dari link verifikasi.
+// @Summary Konfirmasi email
+// @Description Menandai email pengguna sebagai terverifikasi menggunakan token dari email verifikasi.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param verify body VerifyEmailRequest true "Token verifikasi"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "Token tidak valid atau kedaluwarsa"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/email/verify [post]
+func (c *AuthController) VerifyEmail(ctx *fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Token is required"})
+	}
+
+	claims, err := c.parsePurposeToken(req.Token, tokenPurposeEmailVerification)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidToken, Message: "Verification link is invalid or has expired."})
+	}
+
+	if err := c.UserDao.MarkEmailVerified(ctx.Context(), claims.UserID, claims.Email); err != nil {
+		logrus.WithError(err).Warnf("Gagal memverifikasi email untuk user ID %d", claims.UserID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidToken, Message: "Verification link is invalid or has expired."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.email_verification.success", "message": "Email verified successfully."})
+}
+
+// sendVerificationEmail membuat token verifikasi dan mengirimkan link-nya ke email pengguna.
+func (c *AuthController) sendVerificationEmail(ctx context.Context, user *tables.User) error {
+	token, err := c.signPurposeToken(tokenPurposeEmailVerification, user.UserId, user.Email, config.Cfg.Auth.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.Cfg.App.PublicURL, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKlik link berikut untuk memverifikasi email Anda:\n%s\n\nLink ini berlaku selama %d jam. Abaikan email ini jika Anda tidak merasa mendaftar di %s.\n",
+		displayName(user), link, int(config.Cfg.Auth.EmailVerificationTTL.Hours()), config.Cfg.App.Name,
+	)
+
+	return c.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun " + config.Cfg.App.Name,
+		Body:    body,
+	})
+}
+
+// displayName memilih nama yang paling ramah untuk sapaan di email.
+func displayName(user *tables.User) string {
+	if user.FullName != "" {
+		return user.FullName
+	}
+	if user.Username != nil && *user.Username != "" {
+		return *user.Username
+	}
+	return user.Email
+}
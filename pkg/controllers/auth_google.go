@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/googleauth"
+	"noversystem/pkg/tables"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// GoogleLoginRequest adalah payload untuk login menggunakan akun Google.
+type GoogleLoginRequest struct {
+	IDToken string `json:"idToken" example:"eyJhbGciOiJSUzI1NiIs..."`
+}
+
+// GoogleLogin adalah handler untuk login atau registrasi menggunakan ID token Google.
+// @Summary Login dengan Google
+// @Description Memverifikasi ID token Google, lalu login ke akun yang sudah terhubung, membuat akun baru, atau menautkan akun lokal dengan email terverifikasi yang sama.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param google body GoogleLoginRequest true "ID token dari Google Sign-In"
+// @Success 200 {object} LoginSuccessResponse
+// @Success 202 {object} TwoFactorChallengeResponse "Kode 2FA dibutuhkan"
+// @Failure 400 {object} ErrorResponse "Input tidak valid"
+// @Failure 401 {object} ErrorResponse "ID token tidak valid"
+// @Failure 409 {object} ErrorResponse "Email sudah terdaftar sebagai akun lokal"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/google [post]
+func (c *AuthController) GoogleLogin(ctx *fiber.Ctx) error {
+	var req GoogleLoginRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	req.IDToken = strings.TrimSpace(req.IDToken)
+	if req.IDToken == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Google ID token is required"})
+	}
+
+	// 1. Verifikasi ID token terhadap JWKS Google
+	claims, err := c.GoogleVerifier.Verify(ctx.Context(), req.IDToken)
+	if err != nil {
+		if errors.Is(err, googleauth.ErrNotConfigured) {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthGoogleNotConfigured, Message: "Google sign-in is not configured"})
+		}
+		logrus.WithError(err).Warn("Verifikasi ID token Google gagal")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthGoogleTokenInvalid, Message: "Invalid Google ID token"})
+	}
+
+	// 2. Akun Google yang sudah pernah login: user_code berisi Google ID
+	user, err := c.UserDao.FindUserByUserCode(ctx.Context(), claims.Subject, "google")
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	if user == nil {
+		// 3. Belum ada akun Google, cek apakah email sudah dipakai akun lain
+		existing, err := c.UserDao.FindAccountByEmail(ctx.Context(), claims.Email)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+		}
+
+		if existing != nil {
+			// Akun lokal hanya ditautkan jika kedua pihak sudah memverifikasi email yang sama.
+			// Tanpa itu, siapa pun bisa mendaftarkan email orang lain lebih dulu lalu
+			// mengambil alih akun Google korban (atau sebaliknya).
+			if existing.LoginWith != "local" || !bool(claims.EmailVerified) || !existing.IsEmailVerified {
+				return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{
+					Code:    constants.ErrCodeAuthEmailRegisteredLocal,
+					Message: "This email is already registered. Please log in with your password and verify your email before using Google sign-in.",
+				})
+			}
+			if err := c.UserDao.LinkGoogleAccount(ctx.Context(), existing.UserId, claims.Subject); err != nil {
+				logrus.WithError(err).Errorf("Gagal menautkan akun Google ke user ID %d", existing.UserId)
+				return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to link Google account"})
+			}
+			existing.UserCode = claims.Subject
+			existing.LoginWith = "google"
+			user = existing
+		} else {
+			// 4. Buat akun baru dari data profil Google
+			newUser := &tables.User{
+				UserCode:        claims.Subject,
+				Email:           normalizeEmail(claims.Email),
+				FullName:        claims.Name,
+				AvatarURL:       claims.Picture,
+				LoginWith:       "google",
+				IsEmailVerified: bool(claims.EmailVerified),
+				FlgAuthor:       "N",
+			}
+			newID, err := c.UserDao.RegisterUser(ctx.Context(), newUser)
+			if err != nil {
+				logrus.WithError(err).Error("Gagal mendaftarkan pengguna Google")
+				return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "Failed to register user, email may already exist"})
+			}
+			newUser.UserId = newID
+			user = newUser
+		}
+	}
+
+	user.Password = ""
+
+	twoFactorEnabled, err := c.TwoFactorDao.IsEnabled(ctx.Context(), user.UserId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if twoFactorEnabled {
+		return c.respondTwoFactorChallenge(ctx, user)
+	}
+
+	response, errResp := c.createSession(ctx, user, false)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(errResp)
+	}
+
+	return ctx.JSON(response)
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"math"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/loginguard"
+	"noversystem/pkg/tables"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const loginFailureInvalidCredentials = "invalid_credentials"
+
+// loginGuardKey menentukan key throttle akun: berdasarkan user ID jika identifier cocok dengan akun,
+// sehingga username dan email akun yang sama berbagi hitungan, atau identifier mentah jika tidak.
+func loginGuardKey(identifier string, user *tables.User) string {
+	if user != nil {
+		return loginguard.UserKey(user.UserId)
+	}
+	return loginguard.AccountKey(identifier)
+}
+
+// checkLoginGuard memeriksa apakah akun dan IP boleh mencoba login.
+// Jika store throttle bermasalah, login tetap diizinkan (fail open) agar gangguan store tidak mengunci semua pengguna.
+func (c *AuthController) checkLoginGuard(ctx *fiber.Ctx, guardKey string) loginguard.Decision {
+	decision, err := c.LoginGuard.Check(ctx.Context(), guardKey, ctx.IP())
+	if err != nil {
+		logrus.WithError(err).Error("Gagal memeriksa throttle login, percobaan tetap diizinkan")
+		return loginguard.Decision{Allowed: true}
+	}
+	return decision
+}
+
+// failLogin mencatat kegagalan login dan mengembalikan respons yang sesuai.
+// Respons untuk akun yang tidak ada dan password salah sengaja sama agar tidak membocorkan akun yang terdaftar.
+func (c *AuthController) failLogin(ctx *fiber.Ctx, identifier, guardKey string, userId *int64) error {
+	c.recordLoginAttempt(ctx, identifier, userId, loginFailureInvalidCredentials)
+
+	decision, err := c.LoginGuard.Fail(ctx.Context(), guardKey, ctx.IP())
+	if err != nil {
+		logrus.WithError(err).Error("Gagal mencatat kegagalan login ke throttle store")
+	} else if decision.Locked {
+		return respondLoginThrottled(ctx, decision)
+	}
+
+	return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Invalid username, email, or password"})
+}
+
+// succeedLogin mereset hitungan kegagalan akun dan mencatat login yang berhasil.
+func (c *AuthController) succeedLogin(ctx *fiber.Ctx, identifier, guardKey string, userId int64) {
+	if err := c.LoginGuard.Succeed(ctx.Context(), guardKey); err != nil {
+		logrus.WithError(err).Error("Gagal mereset throttle login")
+	}
+	c.recordLoginAttempt(ctx, identifier, &userId, "")
+}
+
+// recordLoginAttempt menulis jejak audit. Kegagalan menulis audit hanya dicatat di log
+// dan tidak menggagalkan login.
+func (c *AuthController) recordLoginAttempt(ctx *fiber.Ctx, identifier string, userId *int64, failureReason string) {
+	attempt := &tables.LoginAttempt{
+		Identifier: identifier,
+		UserID:     userId,
+		IPAddress:  ctx.IP(),
+		UserAgent:  ctx.Get(fiber.HeaderUserAgent),
+		Success:    failureReason == "",
+	}
+	if failureReason != "" {
+		attempt.FailureReason = &failureReason
+	}
+
+	if err := c.LoginAttemptDao.RecordAttempt(ctx.Context(), attempt); err != nil {
+		logrus.WithError(err).Warnf("Gagal mencatat percobaan login untuk '%s'", identifier)
+	}
+}
+
+// loginFailureReason mengubah keputusan throttle menjadi alasan yang disimpan di jejak audit.
+func loginFailureReason(decision loginguard.Decision) string {
+	if decision.Locked {
+		return constants.ErrCodeAuthAccountLocked
+	}
+	return constants.ErrCodeAuthTooManyAttempts
+}
+
+// respondLoginThrottled mengirim 429 beserta header Retry-After dalam detik.
+func respondLoginThrottled(ctx *fiber.Ctx, decision loginguard.Decision) error {
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+	if decision.Locked {
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+			Code:    constants.ErrCodeAuthAccountLocked,
+			Message: "Too many failed login attempts. The account is temporarily locked, try again in " + strconv.Itoa(retryAfter) + " seconds.",
+		})
+	}
+	return ctx.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+		Code:    constants.ErrCodeAuthTooManyAttempts,
+		Message: "Too many login attempts, try again in " + strconv.Itoa(retryAfter) + " seconds.",
+	})
+}
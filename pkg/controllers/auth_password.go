@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/mailer"
+	"noversystem/pkg/tables"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+// ForgotPasswordRequest adalah payload untuk meminta link reset password.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest adalah payload untuk mengganti password dengan token dari email.
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ChangePasswordRequest adalah payload untuk mengganti password oleh pengguna yang login.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// ForgotPassword adalah handler untuk mengirim link reset password ke email pengguna.
+// @Summary Lupa password
+// @Description Mengirim link reset password jika email terdaftar sebagai akun dengan password. Response selalu sama agar tidak membocorkan email yang terdaftar.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param forgot body ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "Input tidak valid"
+// @Router /auth/forgot-password [post]
+func (c *AuthController) ForgotPassword(ctx *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Email is required"})
+	}
+
+	response := fiber.Map{"code": "auth.forgot_password.sent", "message": "If the email is registered, a password reset link has been sent."}
+
+	user, err := c.UserDao.FindUserByEmail(ctx.Context(), req.Email)
+	if err != nil {
+		logrus.WithError(err).Error("Gagal mencari pengguna untuk reset password")
+		return ctx.JSON(response)
+	}
+	if user == nil {
+		return ctx.JSON(response)
+	}
+
+	if err := c.sendPasswordResetEmail(ctx.Context(), user); err != nil {
+		logrus.WithError(err).Errorf("Gagal mengirim email reset password untuk user ID %d", user.UserId)
+	}
+
+	return ctx.JSON(response)
+}
+
+// ResetPassword adalah handler untuk mengganti password menggunakan token reset.
+// @Summary Reset password
+// @Description Mengganti password menggunakan token dari email lupa password. Token hanya bisa dipakai sekali dan semua sesi pengguna akan dicabut.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param reset body ResetPasswordRequest true "Token reset dan password baru"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "Token tidak valid atau password terlalu lemah"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/reset-password [post]
+func (c *AuthController) ResetPassword(ctx *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" || req.NewPassword == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Token and new password are required"})
+	}
+	if errResp := validatePassword(req.NewPassword); errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to hash password"})
+	}
+
+	userId, err := c.PasswordResetDao.ResetPassword(ctx.Context(), hashToken(req.Token), string(hashedPassword))
+	if err != nil {
+		if errors.Is(err, dao.ErrResetTokenInvalid) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidToken, Message: "Reset link is invalid or has expired."})
+		}
+		logrus.WithError(err).Error("Gagal mereset password")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to reset password."})
+	}
+
+	logrus.Infof("Password user ID %d direset, semua sesi dicabut", userId)
+	return ctx.JSON(fiber.Map{"code": "auth.reset_password.success", "message": "Password has been reset. Please log in again."})
+}
+
+// ChangePassword adalah handler untuk mengganti password oleh pengguna yang sedang login.
+// @Summary Ganti password
+// @Description Mengganti password setelah memverifikasi password saat ini. Semua sesi lain milik pengguna akan dicabut.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param change body ChangePasswordRequest true "Password saat ini dan password baru"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "Input tidak valid atau password terlalu lemah"
+// @Failure 401 {object} ErrorResponse "Password saat ini salah"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/change-password [post]
+func (c *AuthController) ChangePassword(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var req ChangePasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Current password and new password are required"})
+	}
+	if errResp := validatePassword(req.NewPassword); errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+
+	user, err := c.UserDao.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+	// Akun Google tanpa password tidak punya password saat ini untuk dicocokkan.
+	if user.Password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidCredentials, Message: "Current password is incorrect"})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to hash password"})
+	}
+	if err := c.UserDao.UpdatePassword(ctx.Context(), userId, string(hashedPassword)); err != nil {
+		logrus.WithError(err).Errorf("Gagal mengganti password user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to change password."})
+	}
+
+	if _, err := c.SessionDao.RevokeOtherUserSessions(ctx.Context(), userId, sessionId); err != nil {
+		logrus.WithError(err).Errorf("Gagal mencabut sesi lain user ID %d setelah ganti password", userId)
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.change_password.success", "message": "Password changed successfully."})
+}
+
+// validatePassword memastikan password baru memenuhi aturan minimal.
+func validatePassword(password string) *ErrorResponse {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return &ErrorResponse{Code: constants.ErrCodeAuthWeakPassword, Message: fmt.Sprintf("Password must be at least %d characters", minPasswordLength)}
+	}
+	return nil
+}
+
+// sendPasswordResetEmail membuat token reset sekali pakai dan mengirimkan link-nya ke email pengguna.
+func (c *AuthController) sendPasswordResetEmail(ctx context.Context, user *tables.User) error {
+	token, tokenHash, err := generateRefreshToken()
+	if err != nil {
+		return err
+	}
+	if err := c.PasswordResetDao.CreateResetToken(ctx, user.UserId, tokenHash, time.Now().Add(config.Cfg.Auth.PasswordResetTTL)); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.Cfg.App.PublicURL, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKami menerima permintaan untuk mengatur ulang password akun Anda. Klik link berikut untuk membuat password baru:\n%s\n\nLink ini hanya bisa dipakai sekali dan berlaku selama %d menit. Abaikan email ini jika Anda tidak meminta reset password.\n",
+		displayName(user), link, int(config.Cfg.Auth.PasswordResetTTL.Minutes()),
+	)
+
+	return c.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun " + config.Cfg.App.Name,
+		Body:    body,
+	})
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// headerDeviceName adalah header opsional dari aplikasi klien untuk memberi nama perangkat (misalnya "Pixel 8 milik Budi").
+const headerDeviceName = "X-Device-Name"
+
+const maxDeviceNameLength = 100
+
+// GetMySessions mengembalikan semua sesi aktif milik pengguna.
+// @Summary Daftar sesi aktif
+// @Description Mengembalikan semua perangkat tempat pengguna sedang login, yang terakhir aktif lebih dulu. Sesi yang sedang dipakai ditandai isCurrent.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} tables.ActiveSession
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/sessions [get]
+func (c *AuthController) GetMySessions(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	sessions, err := c.SessionDao.GetActiveSessions(ctx.Context(), userId)
+	if err != nil {
+		logrus.WithError(err).Errorf("Gagal mengambil sesi user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	for i := range sessions {
+		sessions[i].IsCurrent = sessions[i].SessionID == sessionId
+	}
+
+	return ctx.JSON(nonNilSlice(sessions))
+}
+
+// RevokeMySession mencabut satu sesi milik pengguna.
+// @Summary Cabut satu sesi
+// @Description Mengeluarkan satu perangkat. Access token dan refresh token sesi tersebut langsung tidak berlaku. Mencabut sesi yang sedang dipakai sama dengan logout.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Param sessionId path int true "ID Sesi"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "ID sesi tidak valid"
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 404 {object} ErrorResponse "Sesi tidak ditemukan atau sudah dicabut"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/sessions/{sessionId} [delete]
+func (c *AuthController) RevokeMySession(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	targetSessionId, err := ctx.ParamsInt("sessionId")
+	if err != nil || targetSessionId <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid session ID"})
+	}
+
+	if err := c.SessionDao.RevokeSession(ctx.Context(), int64(targetSessionId), userId); err != nil {
+		if errors.Is(err, dao.ErrSessionNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeAuthSessionRevoked, Message: "Session not found or already revoked."})
+		}
+		logrus.WithError(err).Errorf("Gagal mencabut sesi %d user ID %d", targetSessionId, userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to revoke session."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.session.revoked", "message": "Session revoked."})
+}
+
+// RevokeOtherSessions mencabut semua sesi milik pengguna kecuali sesi yang sedang dipakai.
+// @Summary Cabut semua sesi lain
+// @Description Mengeluarkan semua perangkat lain. Sesi yang sedang dipakai tetap aktif.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{code=string,message=string,revokedSessions=int}
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/sessions/revoke-others [post]
+func (c *AuthController) RevokeOtherSessions(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	revoked, err := c.SessionDao.RevokeOtherUserSessions(ctx.Context(), userId, sessionId)
+	if err != nil {
+		logrus.WithError(err).Errorf("Gagal mencabut sesi lain user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to revoke sessions."})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.sessions.revoked_others", "message": "Logged out from all other devices.", "revokedSessions": revoked})
+}
+
+// deviceNameFromRequest mengambil nama perangkat dari header X-Device-Name. Jika kosong,
+// nama ditebak dari user agent, misalnya "Chrome di Windows".
+func deviceNameFromRequest(ctx *fiber.Ctx) string {
+	if name := strings.TrimSpace(ctx.Get(headerDeviceName)); name != "" {
+		if runes := []rune(name); len(runes) > maxDeviceNameLength {
+			name = string(runes[:maxDeviceNameLength])
+		}
+		return name
+	}
+	return deviceNameFromUserAgent(ctx.Get(fiber.HeaderUserAgent))
+}
+
+// deviceNameFromUserAgent menebak browser dan sistem operasi dari user agent.
+// Urutan pengecekan penting karena user agent Edge dan Opera juga memuat "Chrome",
+// dan Chrome juga memuat "Safari".
+func deviceNameFromUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Perangkat tidak dikenal"
+	}
+
+	browser := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			platform = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " di " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Perangkat tidak dikenal"
+	}
+}
@@ -0,0 +1,478 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"noversystem/pkg/totp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tokenPurposeLoginTwoFactor = "login_2fa"
+	loginFailureInvalidCode    = "invalid_two_factor_code"
+
+	// totpSkew adalah jumlah langkah waktu (30 detik) sebelum/sesudah yang masih diterima.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+// TwoFactorChallengeResponse dikirim saat password benar tetapi akun memakai 2FA.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int64  `json:"expiresIn"` // Masa berlaku challenge token dalam detik
+}
+
+// TwoFactorLoginRequest adalah payload langkah kedua login. Isi salah satu dari code atau recoveryCode.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code" example:"123456"`
+	RecoveryCode   string `json:"recoveryCode" example:"abcde-fghij"`
+}
+
+// TwoFactorCodeRequest berisi kode authenticator atau kode pemulihan untuk verifikasi ulang.
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recoveryCode" example:"abcde-fghij"`
+}
+
+// TwoFactorEnrollResponse berisi secret yang harus dimasukkan ke aplikasi authenticator.
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// TwoFactorRecoveryCodesResponse berisi kode pemulihan. Kode hanya ditampilkan sekali.
+type TwoFactorRecoveryCodesResponse struct {
+	Code          string   `json:"code"`
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorStatusResponse adalah status 2FA milik pengguna.
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedDatetime      *time.Time `json:"confirmedDatetime,omitempty"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+}
+
+// VerifyLoginTwoFactor adalah langkah kedua login untuk akun yang memakai 2FA.
+// @Summary Verifikasi 2FA saat login
+// @Description Menukar challengeToken dari /auth/login atau /auth/google dan kode authenticator (atau kode pemulihan sekali pakai) dengan access token dan refresh token.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param verify body TwoFactorLoginRequest true "Challenge token dan kode 2FA"
+// @Success 200 {object} LoginSuccessResponse
+// @Failure 400 {object} ErrorResponse "Input tidak valid"
+// @Failure 401 {object} ErrorResponse "Challenge token atau kode salah"
+// @Failure 429 {object} ErrorResponse "Terlalu banyak percobaan gagal, lihat header Retry-After"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/login/2fa [post]
+func (c *AuthController) VerifyLoginTwoFactor(ctx *fiber.Ctx) error {
+	var req TwoFactorLoginRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	if req.ChallengeToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Challenge token and a code or recovery code are required"})
+	}
+
+	claims, err := c.parsePurposeToken(req.ChallengeToken, tokenPurposeLoginTwoFactor)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidToken, Message: "Login challenge is invalid or has expired, please log in again"})
+	}
+
+	// Kode 6 digit mudah ditebak, jadi percobaan dibatasi per akun dan per IP seperti password
+	guardKey := twoFactorGuardKey(claims.UserID)
+	if decision := c.checkLoginGuard(ctx, guardKey); !decision.Allowed {
+		c.recordLoginAttempt(ctx, claims.Email, &claims.UserID, loginFailureReason(decision))
+		return respondLoginThrottled(ctx, decision)
+	}
+
+	verified, err := c.verifySecondFactor(ctx, claims.UserID, req.Code, req.RecoveryCode)
+	if err != nil {
+		logrus.WithError(err).Errorf("Gagal memverifikasi 2FA user ID %d", claims.UserID)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if !verified {
+		c.recordLoginAttempt(ctx, claims.Email, &claims.UserID, loginFailureInvalidCode)
+		decision, err := c.LoginGuard.Fail(ctx.Context(), guardKey, ctx.IP())
+		if err != nil {
+			logrus.WithError(err).Error("Gagal mencatat kegagalan 2FA ke throttle store")
+		} else if decision.Locked {
+			return respondLoginThrottled(ctx, decision)
+		}
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorInvalid, Message: "Invalid two-factor code"})
+	}
+
+	user, err := c.UserDao.FindUserByID(ctx.Context(), claims.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthInvalidToken, Message: "User no longer exists"})
+	}
+	user.Password = ""
+	c.succeedLogin(ctx, claims.Email, guardKey, user.UserId)
+
+	response, errResp := c.createSession(ctx, user, true)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(errResp)
+	}
+	return ctx.JSON(response)
+}
+
+// GetTwoFactorStatus mengembalikan status 2FA milik pengguna yang sedang login.
+// @Summary Status 2FA
+// @Description Mengembalikan apakah 2FA aktif dan jumlah kode pemulihan yang belum dipakai.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} TwoFactorStatusResponse
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa [get]
+func (c *AuthController) GetTwoFactorStatus(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	userTOTP, err := c.TwoFactorDao.GetTOTP(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	response := TwoFactorStatusResponse{Enabled: userTOTP.IsConfirmed()}
+	if response.Enabled {
+		response.ConfirmedDatetime = userTOTP.ConfirmedDatetime
+		if response.RecoveryCodesRemaining, err = c.TwoFactorDao.CountRecoveryCodes(ctx.Context(), userId); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+		}
+	}
+	return ctx.JSON(response)
+}
+
+// EnrollTwoFactor memulai pendaftaran 2FA.
+// @Summary Daftar 2FA
+// @Description Membuat secret TOTP baru beserta provisioning URI (otpauth://) untuk dipindai aplikasi authenticator. 2FA belum aktif sampai dikonfirmasi lewat /auth/2fa/confirm. Memanggil ulang sebelum konfirmasi akan mengganti secret.
+// @Tags Authentication
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} TwoFactorEnrollResponse
+// @Failure 401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure 409 {object} ErrorResponse "2FA sudah aktif"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa/enroll [post]
+func (c *AuthController) EnrollTwoFactor(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	user, err := c.UserDao.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to generate secret"})
+	}
+	if err := c.TwoFactorDao.SaveEnrollment(ctx.Context(), userId, secret); err != nil {
+		if errors.Is(err, dao.ErrTwoFactorAlreadyEnabled) {
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorEnabled, Message: "Two-factor authentication is already enabled"})
+		}
+		logrus.WithError(err).Errorf("Gagal menyimpan pendaftaran 2FA user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	return ctx.JSON(TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(config.Cfg.TwoFactor.Issuer, user.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor mengaktifkan 2FA setelah pengguna memasukkan kode pertama dari authenticator.
+// @Summary Konfirmasi 2FA
+// @Description Memverifikasi kode dari secret yang baru didaftarkan, mengaktifkan 2FA, dan mengembalikan kode pemulihan sekali pakai. Simpan kode pemulihan, kode tidak ditampilkan lagi.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param confirm body TwoFactorCodeRequest true "Kode dari aplikasi authenticator"
+// @Success 200 {object} TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse "Belum mendaftar atau input tidak valid"
+// @Failure 401 {object} ErrorResponse "Kode salah"
+// @Failure 409 {object} ErrorResponse "2FA sudah aktif"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa/confirm [post]
+func (c *AuthController) ConfirmTwoFactor(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var req TwoFactorCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	if req.Code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Code is required"})
+	}
+
+	userTOTP, err := c.TwoFactorDao.GetTOTP(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if userTOTP == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorNotEnabled, Message: "Start enrollment via /auth/2fa/enroll first"})
+	}
+	if userTOTP.IsConfirmed() {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorEnabled, Message: "Two-factor authentication is already enabled"})
+	}
+
+	step, ok := totp.Validate(userTOTP.Secret, req.Code, time.Now(), totpSkew, userTOTP.LastUsedStep)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorInvalid, Message: "Invalid two-factor code"})
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to generate recovery codes"})
+	}
+	if err := c.TwoFactorDao.Confirm(ctx.Context(), userId, sessionId, step, hashes); err != nil {
+		if errors.Is(err, dao.ErrTwoFactorAlreadyEnabled) {
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorEnabled, Message: "Two-factor authentication is already enabled"})
+		}
+		logrus.WithError(err).Errorf("Gagal mengaktifkan 2FA user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	return ctx.JSON(TwoFactorRecoveryCodesResponse{
+		Code:          "auth.2fa.enabled",
+		Message:       "Two-factor authentication enabled. Store these recovery codes somewhere safe.",
+		RecoveryCodes: codes,
+	})
+}
+
+// VerifyTwoFactor memverifikasi ulang 2FA pada sesi yang sedang dipakai (step-up).
+// @Summary Verifikasi ulang 2FA
+// @Description Dipakai sebelum aksi sensitif (misalnya mengubah data rekening pencairan) yang membalas dengan kode two_factor_required. Verifikasi berlaku beberapa menit untuk sesi ini.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param verify body TwoFactorCodeRequest true "Kode authenticator atau kode pemulihan"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "2FA belum aktif atau input tidak valid"
+// @Failure 401 {object} ErrorResponse "Kode salah"
+// @Failure 429 {object} ErrorResponse "Terlalu banyak percobaan gagal, lihat header Retry-After"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa/verify [post]
+func (c *AuthController) VerifyTwoFactor(ctx *fiber.Ctx) error {
+	userId, okUser := ctx.Locals("userId").(int64)
+	sessionId, okSession := ctx.Locals("sessionId").(int64)
+	if !okUser || !okSession {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	if ok, err := c.requireSecondFactor(ctx, userId); !ok {
+		return err
+	}
+	if err := c.TwoFactorDao.MarkSessionVerified(ctx.Context(), sessionId, userId); err != nil {
+		logrus.WithError(err).Errorf("Gagal menandai sesi %d lolos 2FA", sessionId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	return ctx.JSON(fiber.Map{"code": "auth.2fa.verified", "message": "Two-factor verification succeeded."})
+}
+
+// RegenerateRecoveryCodes membuat kode pemulihan baru dan membatalkan semua kode lama.
+// @Summary Buat ulang kode pemulihan 2FA
+// @Description Membatalkan semua kode pemulihan lama dan membuat kode baru. Membutuhkan kode authenticator yang valid.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param verify body TwoFactorCodeRequest true "Kode authenticator"
+// @Success 200 {object} TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse "2FA belum aktif atau input tidak valid"
+// @Failure 401 {object} ErrorResponse "Kode salah"
+// @Failure 429 {object} ErrorResponse "Terlalu banyak percobaan gagal, lihat header Retry-After"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa/recovery-codes [post]
+func (c *AuthController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	if ok, err := c.requireSecondFactor(ctx, userId); !ok {
+		return err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to generate recovery codes"})
+	}
+	if err := c.TwoFactorDao.ReplaceRecoveryCodes(ctx.Context(), userId, hashes); err != nil {
+		logrus.WithError(err).Errorf("Gagal membuat ulang kode pemulihan user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	return ctx.JSON(TwoFactorRecoveryCodesResponse{
+		Code:          "auth.2fa.recovery_codes_regenerated",
+		Message:       "New recovery codes generated. Previous codes no longer work.",
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor mematikan 2FA.
+// @Summary Nonaktifkan 2FA
+// @Description Mematikan 2FA dan menghapus semua kode pemulihan. Membutuhkan kode authenticator atau kode pemulihan yang valid.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param verify body TwoFactorCodeRequest true "Kode authenticator atau kode pemulihan"
+// @Success 200 {object} object{code=string,message=string}
+// @Failure 400 {object} ErrorResponse "2FA belum aktif atau input tidak valid"
+// @Failure 401 {object} ErrorResponse "Kode salah"
+// @Failure 429 {object} ErrorResponse "Terlalu banyak percobaan gagal, lihat header Retry-After"
+// @Failure 500 {object} ErrorResponse "Error internal server"
+// @Router /auth/2fa/disable [post]
+func (c *AuthController) DisableTwoFactor(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	if ok, err := c.requireSecondFactor(ctx, userId); !ok {
+		return err
+	}
+	if err := c.TwoFactorDao.Disable(ctx.Context(), userId); err != nil {
+		logrus.WithError(err).Errorf("Gagal menonaktifkan 2FA user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	logrus.Infof("2FA user ID %d dinonaktifkan", userId)
+	return ctx.JSON(fiber.Map{"code": "auth.2fa.disabled", "message": "Two-factor authentication disabled."})
+}
+
+// respondTwoFactorChallenge mengirim challenge token untuk langkah kedua login.
+func (c *AuthController) respondTwoFactorChallenge(ctx *fiber.Ctx, user *tables.User) error {
+	ttl := config.Cfg.TwoFactor.ChallengeTTL
+	challengeToken, err := c.signPurposeToken(tokenPurposeLoginTwoFactor, user.UserId, user.Email, ttl)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeAuthTokenCreation, Message: "Failed to create token"})
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+		ExpiresIn:         int64(ttl.Seconds()),
+	})
+}
+
+// requireSecondFactor membaca TwoFactorCodeRequest dari body dan memverifikasinya untuk pengguna
+// yang sudah login, dengan throttle yang sama seperti langkah kedua login.
+// Jika hasilnya false, respons error sudah dikirim dan handler cukup mengembalikan error-nya.
+func (c *AuthController) requireSecondFactor(ctx *fiber.Ctx, userId int64) (bool, error) {
+	var req TwoFactorCodeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse JSON"})
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "A code or recovery code is required"})
+	}
+
+	enabled, err := c.TwoFactorDao.IsEnabled(ctx.Context(), userId)
+	if err != nil {
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if !enabled {
+		return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorNotEnabled, Message: "Two-factor authentication is not enabled"})
+	}
+
+	guardKey := twoFactorGuardKey(userId)
+	if decision := c.checkLoginGuard(ctx, guardKey); !decision.Allowed {
+		return false, respondLoginThrottled(ctx, decision)
+	}
+
+	verified, err := c.verifySecondFactor(ctx, userId, req.Code, req.RecoveryCode)
+	if err != nil {
+		logrus.WithError(err).Errorf("Gagal memverifikasi 2FA user ID %d", userId)
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if !verified {
+		decision, err := c.LoginGuard.Fail(ctx.Context(), guardKey, ctx.IP())
+		if err != nil {
+			logrus.WithError(err).Error("Gagal mencatat kegagalan 2FA ke throttle store")
+		} else if decision.Locked {
+			return false, respondLoginThrottled(ctx, decision)
+		}
+		return false, ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeAuthTwoFactorInvalid, Message: "Invalid two-factor code"})
+	}
+
+	if err := c.LoginGuard.Succeed(ctx.Context(), guardKey); err != nil {
+		logrus.WithError(err).Error("Gagal mereset throttle 2FA")
+	}
+	return true, nil
+}
+
+// verifySecondFactor mencocokkan kode TOTP (dengan perlindungan pemakaian ulang) atau memakai satu kode pemulihan.
+func (c *AuthController) verifySecondFactor(ctx *fiber.Ctx, userId int64, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		userTOTP, err := c.TwoFactorDao.GetTOTP(ctx.Context(), userId)
+		if err != nil || !userTOTP.IsConfirmed() {
+			return false, err
+		}
+		step, ok := totp.Validate(userTOTP.Secret, code, time.Now(), totpSkew, userTOTP.LastUsedStep)
+		if !ok {
+			return false, nil
+		}
+		return c.TwoFactorDao.UseStep(ctx.Context(), userId, step)
+	}
+	return c.TwoFactorDao.UseRecoveryCode(ctx.Context(), userId, hashToken(normalizeRecoveryCode(recoveryCode)))
+}
+
+// twoFactorGuardKey adalah identifier throttle untuk percobaan kode 2FA, terpisah dari hitungan password.
+func twoFactorGuardKey(userId int64) string {
+	return fmt.Sprintf("2fa:%d", userId)
+}
+
+// generateRecoveryCodes membuat kode pemulihan acak berformat xxxxx-xxxxx beserta hash SHA-256-nya.
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789" // tanpa karakter yang mirip (0/o, 1/l/i)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		for j := range buf {
+			buf[j] = alphabet[int(buf[j])%len(alphabet)]
+		}
+		code := string(buf[:5]) + "-" + string(buf[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode mengabaikan huruf besar/kecil, spasi, dan tanda hubung saat mencocokkan kode pemulihan.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
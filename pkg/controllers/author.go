@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthorController menangani profil publik penulis.
+type AuthorController struct {
+	userDAO   *dao.UserDao
+	followDAO *dao.AuthorFollowDao
+	log       *logrus.Logger
+}
+
+func NewAuthorController(userDAO *dao.UserDao, followDAO *dao.AuthorFollowDao) *AuthorController {
+	return &AuthorController{
+		userDAO:   userDAO,
+		followDAO: followDAO,
+		log:       logrus.New(),
+	}
+}
+
+// GetAuthorProfile adalah handler publik untuk melihat profil penulis.
+// @Summary      Profil Publik Penulis
+// @Description  Mengambil nama pena, avatar, bio, dan statistik penulis (jumlah buku terbit, total pembaca, rata-rata rating, jumlah pengikut). Data pribadi seperti email, telepon, dan rekening tidak pernah ditampilkan.
+// @Tags         Author
+// @Produce      json
+// @Param        authorId path int true "ID Penulis"
+// @Success      200 {object} tables.AuthorProfile
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      404 {object} ErrorResponse "Penulis tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/authors/{authorId} [GET]
+func (c *AuthorController) GetAuthorProfile(ctx *fiber.Ctx) error {
+	authorId, err := strconv.ParseInt(ctx.Params("authorId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid author ID."})
+	}
+
+	profile, err := c.userDAO.GetAuthorProfile(ctx.Context(), authorId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil profil penulis %d", authorId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve author profile."})
+	}
+	if profile == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "Author not found."})
+	}
+
+	// Locals userId diisi oleh middleware OptionalAuth jika pembaca sudah login
+	if viewerId, ok := ctx.Locals("userId").(int64); ok && viewerId != 0 && viewerId != authorId {
+		following, err := c.followDAO.IsFollowing(ctx.Context(), viewerId, authorId)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to check follow status."})
+		}
+		profile.IsFollowing = &following
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(profile)
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthorApplicationController menangani review pengajuan penulis oleh admin.
+type AuthorApplicationController struct {
+	applicationDAO *dao.AuthorApplicationDao
+	log            *logrus.Logger
+}
+
+func NewAuthorApplicationController(applicationDAO *dao.AuthorApplicationDao) *AuthorApplicationController {
+	return &AuthorApplicationController{
+		applicationDAO: applicationDAO,
+		log:            logrus.New(),
+	}
+}
+
+// DecideApplicationPayload adalah body untuk menyetujui atau menolak pengajuan penulis.
+type DecideApplicationPayload struct {
+	Decision string `json:"decision" example:"APPROVED"` // APPROVED atau REJECTED
+	Reason   string `json:"reason"`                      // Wajib diisi jika ditolak
+}
+
+// ListApplications adalah handler untuk menampilkan daftar pengajuan penulis.
+// @Summary      Daftar Pengajuan Penulis (Admin)
+// @Description  Mengambil daftar pengajuan penulis, diurutkan dari yang paling lama. Default hanya menampilkan yang menunggu review.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        status query string false "Filter status: PENDING, APPROVED, REJECTED, atau ALL" default(PENDING)
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(10)
+// @Success      200 {object} tables.PaginatedAuthorApplicationResponse
+// @Failure      400 {object} ErrorResponse "Status tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan admin"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/admin/author-applications [GET]
+func (c *AuthorApplicationController) ListApplications(ctx *fiber.Ctx) error {
+	status := strings.ToUpper(ctx.Query("status", tables.AuthorApplicationPending))
+	switch status {
+	case "ALL":
+		status = ""
+	case tables.AuthorApplicationPending, tables.AuthorApplicationApproved, tables.AuthorApplicationRejected:
+	default:
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid status filter."})
+	}
+
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	applications, err := c.applicationDAO.ListApplications(ctx.Context(), status, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Error("Gagal mengambil daftar pengajuan penulis")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve author applications."})
+	}
+
+	totalItems, err := c.applicationDAO.CountApplications(ctx.Context(), status)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count author applications."})
+	}
+
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	response := tables.PaginatedAuthorApplicationResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Items:      applications,
+	}
+	if response.Items == nil {
+		response.Items = make([]tables.AuthorApplication, 0)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// DecideApplication adalah handler untuk menyetujui atau menolak pengajuan penulis.
+// @Summary      Putuskan Pengajuan Penulis (Admin)
+// @Description  Menyetujui atau menolak pengajuan penulis. Jika disetujui, pengguna langsung menjadi penulis. Pemohon menerima notifikasi hasil keputusan.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        applicationId path int true "ID Pengajuan"
+// @Param        decision body DecideApplicationPayload true "Keputusan dan alasan"
+// @Success      200 {object} tables.AuthorApplication
+// @Failure      400 {object} ErrorResponse "Input tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan admin"
+// @Failure      404 {object} ErrorResponse "Pengajuan tidak ditemukan"
+// @Failure      409 {object} ErrorResponse "Pengajuan sudah diputuskan atau nama pena/nomor telepon sudah digunakan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/admin/author-applications/{applicationId}/decision [POST]
+func (c *AuthorApplicationController) DecideApplication(ctx *fiber.Ctx) error {
+	reviewerId, ok := ctx.Locals("userId").(int64)
+	if !ok || reviewerId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	applicationId, err := strconv.ParseInt(ctx.Params("applicationId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid application ID."})
+	}
+
+	var payload DecideApplicationPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+	payload.Decision = strings.ToUpper(strings.TrimSpace(payload.Decision))
+	payload.Reason = strings.TrimSpace(payload.Reason)
+
+	var approve bool
+	switch payload.Decision {
+	case tables.AuthorApplicationApproved:
+		approve = true
+	case tables.AuthorApplicationRejected:
+		if payload.Reason == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "A reason is required when rejecting an application."})
+		}
+	default:
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Decision must be APPROVED or REJECTED."})
+	}
+
+	application, err := c.applicationDAO.DecideApplication(ctx.Context(), applicationId, reviewerId, approve, payload.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, dao.ErrApplicationNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeAuthorApplicationNotFound, Message: "Author application not found."})
+		case errors.Is(err, dao.ErrApplicationNotPending):
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthorApplicationNotPending, Message: "This application has already been decided."})
+		case errors.Is(err, dao.ErrPenNameTaken):
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeUserPenNameTaken, Message: "The requested pen name is already used by another author, please reject this application."})
+		case errors.Is(err, dao.ErrPhoneTaken):
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "The phone number is already used by another account, please reject this application."})
+		}
+		c.log.WithError(err).Errorf("Gagal memutuskan pengajuan penulis %d", applicationId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to decide author application."})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(application)
+}
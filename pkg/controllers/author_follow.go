@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthorFollowController menangani fitur mengikuti penulis.
+type AuthorFollowController struct {
+	followDAO *dao.AuthorFollowDao
+	log       *logrus.Logger
+}
+
+func NewAuthorFollowController(followDAO *dao.AuthorFollowDao) *AuthorFollowController {
+	return &AuthorFollowController{
+		followDAO: followDAO,
+		log:       logrus.New(),
+	}
+}
+
+// FollowAuthor adalah handler untuk mengikuti penulis.
+// @Summary      Ikuti Penulis
+// @Description  Mengikuti penulis agar mendapat notifikasi saat penulis menerbitkan buku baru. Mengikuti penulis yang sudah diikuti tidak dianggap error.
+// @Tags         Author
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        authorId path int true "ID Penulis"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid atau mengikuti diri sendiri"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Penulis tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/authors/{authorId}/follow [POST]
+func (c *AuthorFollowController) FollowAuthor(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	authorId, err := strconv.ParseInt(ctx.Params("authorId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid author ID."})
+	}
+	if authorId == userId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "You cannot follow yourself."})
+	}
+
+	if err := c.followDAO.Follow(ctx.Context(), userId, authorId); err != nil {
+		if errors.Is(err, dao.ErrAuthorNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "Author not found."})
+		}
+		c.log.WithError(err).Errorf("User %d gagal mengikuti penulis %d", userId, authorId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to follow author."})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "author.follow.success", "message": "You are now following this author."})
+}
+
+// UnfollowAuthor adalah handler untuk berhenti mengikuti penulis.
+// @Summary      Berhenti Mengikuti Penulis
+// @Description  Berhenti mengikuti penulis.
+// @Tags         Author
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        authorId path int true "ID Penulis"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Belum mengikuti penulis ini"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/authors/{authorId}/follow [DELETE]
+func (c *AuthorFollowController) UnfollowAuthor(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	authorId, err := strconv.ParseInt(ctx.Params("authorId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid author ID."})
+	}
+
+	removed, err := c.followDAO.Unfollow(ctx.Context(), userId, authorId)
+	if err != nil {
+		c.log.WithError(err).Errorf("User %d gagal berhenti mengikuti penulis %d", userId, authorId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to unfollow author."})
+	}
+	if !removed {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "You are not following this author."})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "author.unfollow.success", "message": "You have unfollowed this author."})
+}
+
+// GetAuthorFollowers adalah handler publik untuk melihat pengikut seorang penulis.
+// @Summary      Daftar Pengikut Penulis
+// @Description  Mengambil daftar pengikut penulis, yang terbaru lebih dulu. Total pengikut ada di pagination.totalItems.
+// @Tags         Author
+// @Produce      json
+// @Param        authorId path int true "ID Penulis"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedFollowResponse
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/authors/{authorId}/followers [GET]
+func (c *AuthorFollowController) GetAuthorFollowers(ctx *fiber.Ctx) error {
+	authorId, err := strconv.ParseInt(ctx.Params("authorId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid author ID."})
+	}
+	page, limit, offset := offsetPagination(ctx)
+
+	followers, err := c.followDAO.ListFollowers(ctx.Context(), authorId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil pengikut penulis %d", authorId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve followers."})
+	}
+	totalItems, err := c.followDAO.CountFollowers(ctx.Context(), authorId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count followers."})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(newPaginatedFollowResponse(followers, page, limit, totalItems))
+}
+
+// GetMyFollowing adalah handler untuk melihat penulis yang diikuti pengguna yang sedang login.
+// @Summary      Penulis yang Saya Ikuti
+// @Description  Mengambil daftar penulis yang diikuti, yang terbaru lebih dulu. Total ada di pagination.totalItems.
+// @Tags         Author
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedFollowResponse
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/following [GET]
+func (c *AuthorFollowController) GetMyFollowing(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	page, limit, offset := offsetPagination(ctx)
+
+	authors, err := c.followDAO.ListFollowing(ctx.Context(), userId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil penulis yang diikuti user %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve followed authors."})
+	}
+	totalItems, err := c.followDAO.CountFollowing(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count followed authors."})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(newPaginatedFollowResponse(authors, page, limit, totalItems))
+}
+
+func newPaginatedFollowResponse(items []tables.FollowUser, page, limit int, totalItems int64) tables.PaginatedFollowResponse {
+	if items == nil {
+		items = make([]tables.FollowUser, 0)
+	}
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return tables.PaginatedFollowResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Items:      items,
+	}
+}
@@ -1,32 +1,46 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"noversystem/pkg/constants"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/dao"
 	"noversystem/pkg/tables"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	followerFanOutBatchSize = 1000
+	followerFanOutTimeout   = 10 * time.Minute
+)
+
 // BookController menangani logika HTTP yang terkait dengan buku.
 type BookController struct {
 	bookDAO    *dao.BookDao
 	userDAO    *dao.UserDao
 	chapterDAO *dao.ChapterDao
 	reviewDAO  *dao.ReviewDao
+	followDAO  *dao.AuthorFollowDao
+	genreDAO   *dao.GenreDao
 	log        *logrus.Logger
 }
 
 // NewBookController membuat instance baru dari BookController.
-func NewBookController(bookDAO *dao.BookDao, userDAO *dao.UserDao, chapterDAO *dao.ChapterDao, reviewDAO *dao.ReviewDao) *BookController {
+func NewBookController(bookDAO *dao.BookDao, userDAO *dao.UserDao, chapterDAO *dao.ChapterDao, reviewDAO *dao.ReviewDao, followDAO *dao.AuthorFollowDao, genreDAO *dao.GenreDao) *BookController {
 	return &BookController{
 		bookDAO:    bookDAO,
 		userDAO:    userDAO,
 		chapterDAO: chapterDAO,
 		reviewDAO:  reviewDAO,
+		followDAO:  followDAO,
+		genreDAO:   genreDAO,
 		log:        logrus.New(),
 	}
 }
@@ -44,6 +58,13 @@ type BookListResponse struct {
 	BookList []tables.Book `json:"bookList"`
 }
 
+// AuthorBookListResponse adalah daftar buku seorang penulis beserta jumlah pengikutnya.
+type AuthorBookListResponse struct {
+	BookList      []tables.Book `json:"bookList"`
+	FollowerCount int64         `json:"followerCount"`
+	IsFollowing   *bool         `json:"isFollowing,omitempty"` // Hanya diisi jika request membawa token
+}
+
 // CreateBook adalah handler untuk endpoint pembuatan buku baru.
 // @Summary      Buat Buku Baru
 // @Description  Membuat buku baru oleh pengguna yang sudah terotentikasi dan berstatus sebagai penulis.
@@ -122,7 +143,7 @@ func (c *BookController) GetMyBooks(ctx *fiber.Ctx) error {
 // @Tags         Book
 // @Produce      json
 // @Param        authorId path int true "ID dari Penulis"
-// @Success      200 {object} BookListResponse
+// @Success      200 {object} AuthorBookListResponse
 // @Failure      400 {object} ErrorResponse "ID Penulis tidak valid"
 // @Failure      500 {object} ErrorResponse "Error internal server"
 // @Router       /v1/authors/{authorId}/books [GET]
@@ -138,40 +159,68 @@ func (c *BookController) GetBooksByAuthor(ctx *fiber.Ctx) error {
 	if books == nil {
 		books = []tables.Book{}
 	}
-	return ctx.Status(fiber.StatusOK).JSON(BookListResponse{BookList: books})
+	followerCount, err := c.followDAO.CountFollowers(ctx.Context(), authorId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count followers."})
+	}
+
+	response := AuthorBookListResponse{BookList: books, FollowerCount: followerCount}
+	// Locals userId diisi oleh middleware OptionalAuth jika pembaca sudah login
+	if viewerId, ok := ctx.Locals("userId").(int64); ok && viewerId != 0 {
+		following, err := c.followDAO.IsFollowing(ctx.Context(), viewerId, authorId)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to check follow status."})
+		}
+		response.IsFollowing = &following
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
 // GetPublishedBookList adalah handler publik untuk mendapatkan daftar buku dengan pagination.
 // @Summary      Dapatkan Daftar Buku (Publik, Paginasi)
-// @Description  Mengambil daftar semua buku yang sudah dipublikasikan (status 'P', 'C', 'H') dengan sistem pagination.
+// @Description  Mengambil daftar semua buku yang sudah dipublikasikan (status 'P', 'C', 'H') dengan sistem pagination. Total item dan jumlah halaman dihitung dengan filter yang sama. Untuk infinite scroll gunakan nextCursor agar tidak ada buku yang terlewat atau terulang saat buku baru terbit.
 // @Tags         Book
 // @Produce      json
+// @Param        sort query string false "Urutan: newest, most_viewed, top_rated, recently_updated" default(newest)
+// @Param        genreIds query string false "ID genre dipisah koma, buku cocok jika memiliki salah satunya" example(1,4)
+// @Param        status query string false "Status dipisah koma: P (Published), C (Completed), H (On Hold)" example(P,C)
+// @Param        minRating query number false "Rating rata-rata minimal (0-5)"
+// @Param        completed query bool false "Hanya buku yang sudah tamat (status C)"
+// @Param        free query bool false "Hanya buku yang semua chapter terbitnya gratis"
 // @Param        page query int false "Nomor Halaman" default(1)
 // @Param        limit query int false "Jumlah item per halaman" default(10)
+// @Param        cursor query string false "Token nextCursor/prevCursor dari response sebelumnya, menggantikan page"
 // @Success      200 {object} tables.PaginatedBookResponse
+// @Failure      400 {object} ErrorResponse "Filter, urutan, atau cursor tidak valid"
 // @Failure      500 {object} ErrorResponse "Error internal server"
 // @Router       /v1/books [GET]
 func (c *BookController) GetPublishedBookList(ctx *fiber.Ctx) error {
-	page, _ := strconv.Atoi(ctx.Query("page", "1"))
-	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
-	if page < 1 {
-		page = 1
+	sort := dao.BookSort(ctx.Query("sort", string(dao.BookSortNewest)))
+	if !sort.IsValid() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "sort must be one of newest, most_viewed, top_rated or recently_updated."})
 	}
-	if limit > 100 {
-		limit = 100
+	filter, errResp := parseBookListFilter(ctx)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+
+	pageReq, page, errResp := pageRequest(ctx, sort.CursorKey(), 10, 100)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+	books, cursors, err := c.bookDAO.GetPublishedBooks(ctx.Context(), filter, sort, pageReq)
+	if errors.Is(err, cursor.ErrInvalid) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeInvalidCursor, Message: "Invalid or expired cursor."})
 	}
-	offset := (page - 1) * limit
-	books, err := c.bookDAO.GetPublishedBooks(ctx.Context(), limit, offset)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve book list."})
 	}
-	totalItems, err := c.bookDAO.CountPublishedBooks(ctx.Context())
+	totalItems, err := c.bookDAO.CountPublishedBooks(ctx.Context(), filter)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count books."})
 	}
-	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
 	response := tables.PaginatedBookResponse{
-		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Pagination: newPaginationInfo(ctx, pageReq, page, totalItems, cursors),
 		Books:      books,
 	}
 	return ctx.Status(fiber.StatusOK).JSON(response)
@@ -220,11 +269,10 @@ func (c *BookController) GetMyBookDetail(ctx *fiber.Ctx) error {
         chapters = []tables.Chapter{}
     }
 
-	author, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	author, err := c.userDAO.GetPublicAuthor(ctx.Context(), userId)
 	if err != nil || author == nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get author data."})
 	}
-    author.Password = ""
 	
 	reviews, err := c.reviewDAO.GetReviewsByBookID(ctx.Context(), bookId)
     if err != nil {
@@ -237,126 +285,135 @@ func (c *BookController) GetMyBookDetail(ctx *fiber.Ctx) error {
 	response := tables.BookDetailResponse{
 		BookInfo: book,
 		Chapters: chapters,
-		Author:   author,
+		Author:   tables.NewBookAuthor(author),
 		Reviews:  reviews,
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
-// processBookStatus adalah fungsi helper internal untuk validasi umum sebelum mengubah status buku.
-func (c *BookController) processBookStatus(ctx *fiber.Ctx) (int64, int64, *tables.Book, error) {
-	userId, ok := ctx.Locals("userId").(int64)
+// processBookStatus adalah fungsi helper internal untuk validasi umum sebelum mengubah buku milik
+// pengguna yang login. Jika ok bernilai false, response error sudah dikirim dan err harus dikembalikan
+// oleh handler apa adanya.
+func (c *BookController) processBookStatus(ctx *fiber.Ctx) (userId, bookId int64, book *tables.Book, ok bool, err error) {
+	userId, ok = ctx.Locals("userId").(int64)
 	if !ok || userId == 0 {
-		return 0, 0, nil, ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid user token."})
+		return 0, 0, nil, false, ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid user token."})
 	}
-	bookId, err := strconv.ParseInt(ctx.Params("bookId"), 10, 64)
+	bookId, err = strconv.ParseInt(ctx.Params("bookId"), 10, 64)
 	if err != nil {
-		return 0, 0, nil, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
+		return 0, 0, nil, false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
 	}
-	book, err := c.bookDAO.GetBookWithAuthor(ctx.Context(), bookId)
+	book, err = c.bookDAO.GetBookWithAuthor(ctx.Context(), bookId)
 	if err != nil {
-		return 0, 0, nil, ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get book details."})
+		return 0, 0, nil, false, ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get book details."})
 	}
 	if book == nil {
-		return 0, 0, nil, ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+		return 0, 0, nil, false, ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
 	}
 	if book.AuthorID != userId {
-		return 0, 0, nil, ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeBookNotOwner, Message: "You are not the owner of this book."})
+		return 0, 0, nil, false, ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeBookNotOwner, Message: "You are not the owner of this book."})
 	}
-	return userId, bookId, book, nil
+	return userId, bookId, book, true, nil
 }
 
 // PublishBook mempublikasikan sebuah buku.
 // @Summary      Publikasikan Buku
-// @Description  Mengubah status buku menjadi 'Published'. Memerlukan minimal 1 chapter.
+// @Description  Mengubah status buku menjadi 'Published' dari 'Draft', 'On Hold', atau 'Completed'. Memerlukan minimal 1 chapter saat keluar dari 'Draft' atau 'On Hold'.
 // @Tags         Book Management
+// @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        bookId path int true "ID Buku"
+// @Param        body body BookStatusRequest false "Alasan perubahan status (opsional)"
 // @Success      200 {object} object{code=string,message=string}
 // @Failure      400 {object} ErrorResponse "Buku tidak memiliki chapter"
+// @Failure      409 {object} ErrorResponse "Perpindahan status tidak diizinkan"
 // @Router       /v1/books/{bookId}/publish [PATCH]
 func (c *BookController) PublishBook(ctx *fiber.Ctx) error {
-	_, bookId, _, err := c.processBookStatus(ctx)
-	if err != nil {
+	userId, bookId, book, ok, err := c.processBookStatus(ctx)
+	if !ok {
 		return err
 	}
-	chapterCount, err := c.bookDAO.CountChaptersByBookID(ctx.Context(), bookId)
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count chapters."})
-	}
-	if chapterCount == 0 {
-		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBookNoChapters, Message: "Cannot publish a book with no chapters."})
+	if ok, err := c.transitionBookStatus(ctx, userId, bookId, tables.BookStatusPublished); !ok {
+		return err
 	}
-	if err := c.bookDAO.UpdateBookStatus(ctx.Context(), bookId, "P"); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to publish book."})
+
+	// Pengikut hanya diberi tahu saat buku pertama kali terbit, bukan saat publikasi ulang
+	firstPublish, err := c.bookDAO.MarkFirstPublished(ctx.Context(), bookId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal menandai publikasi pertama buku %d", bookId)
+	} else if firstPublish {
+		go c.notifyFollowersOfNewBook(userId, bookId, book.Title)
 	}
 	return ctx.JSON(fiber.Map{"code": "book.publish.success", "message": "Book published successfully."})
 }
 
 // UnpublishBook mengembalikan buku ke status draft.
 // @Summary      Batalkan Publikasi Buku
-// @Description  Mengubah status buku kembali menjadi 'Draft'. Hanya bisa dilakukan pada buku yang sedang 'Published'.
+// @Description  Mengubah status buku kembali menjadi 'Draft'. Hanya bisa dilakukan pada buku yang sedang 'Published' atau 'On Hold'.
 // @Tags         Book Management
+// @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        bookId path int true "ID Buku"
+// @Param        body body BookStatusRequest false "Alasan perubahan status (opsional)"
 // @Success      200 {object} object{code=string,message=string}
+// @Failure      409 {object} ErrorResponse "Perpindahan status tidak diizinkan"
 // @Router       /v1/books/{bookId}/unpublish [PATCH]
 func (c *BookController) UnpublishBook(ctx *fiber.Ctx) error {
-	_, bookId, book, err := c.processBookStatus(ctx)
-	if err != nil {
+	userId, bookId, _, ok, err := c.processBookStatus(ctx)
+	if !ok {
 		return err
 	}
-	if book.Status != "P" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBookNotPublished, Message: "Only published books can be unpublished."})
-	}
-	if err := c.bookDAO.UpdateBookStatus(ctx.Context(), bookId, "D"); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to unpublish book."})
+	if ok, err := c.transitionBookStatus(ctx, userId, bookId, tables.BookStatusDraft); !ok {
+		return err
 	}
 	return ctx.JSON(fiber.Map{"code": "book.unpublish.success", "message": "Book unpublished successfully."})
 }
 
 // CompleteBook menandai buku sebagai selesai.
 // @Summary      Selesaikan Buku
-// @Description  Mengubah status buku menjadi 'Completed'. Hanya bisa dilakukan pada buku yang sedang 'Published'.
+// @Description  Mengubah status buku menjadi 'Completed'. Hanya bisa dilakukan pada buku yang sedang 'Published' atau 'On Hold'.
 // @Tags         Book Management
+// @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        bookId path int true "ID Buku"
+// @Param        body body BookStatusRequest false "Alasan perubahan status (opsional)"
 // @Success      200 {object} object{code=string,message=string}
+// @Failure      409 {object} ErrorResponse "Perpindahan status tidak diizinkan"
 // @Router       /v1/books/{bookId}/complete [PATCH]
 func (c *BookController) CompleteBook(ctx *fiber.Ctx) error {
-	_, bookId, book, err := c.processBookStatus(ctx)
-	if err != nil {
+	userId, bookId, _, ok, err := c.processBookStatus(ctx)
+	if !ok {
 		return err
 	}
-	if book.Status != "P" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBookNotPublished, Message: "Only published books can be marked as completed."})
-	}
-	if err := c.bookDAO.UpdateBookStatus(ctx.Context(), bookId, "C"); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to complete book."})
+	if ok, err := c.transitionBookStatus(ctx, userId, bookId, tables.BookStatusCompleted); !ok {
+		return err
 	}
 	return ctx.JSON(fiber.Map{"code": "book.complete.success", "message": "Book marked as completed."})
 }
 
 // HoldBook menandai buku sebagai ditunda.
 // @Summary      Tunda Buku
-// @Description  Mengubah status buku menjadi 'On Hold'.
+// @Description  Mengubah status buku menjadi 'On Hold'. Hanya bisa dilakukan pada buku yang sedang 'Published'.
 // @Tags         Book Management
+// @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        bookId path int true "ID Buku"
+// @Param        body body BookStatusRequest false "Alasan perubahan status (opsional)"
 // @Success      200 {object} object{code=string,message=string}
+// @Failure      409 {object} ErrorResponse "Perpindahan status tidak diizinkan"
 // @Router       /v1/books/{bookId}/hold [PATCH]
 func (c *BookController) HoldBook(ctx *fiber.Ctx) error {
-	_, bookId, _, err := c.processBookStatus(ctx)
-	if err != nil {
+	userId, bookId, _, ok, err := c.processBookStatus(ctx)
+	if !ok {
 		return err
 	}
-	if err := c.bookDAO.UpdateBookStatus(ctx.Context(), bookId, "H"); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to put book on hold."})
+	if ok, err := c.transitionBookStatus(ctx, userId, bookId, tables.BookStatusOnHold); !ok {
+		return err
 	}
 	return ctx.JSON(fiber.Map{"code": "book.hold.success", "message": "Book put on hold successfully."})
 }
@@ -395,12 +452,11 @@ func (c *BookController) GetPublicBookDetail(ctx *fiber.Ctx) error {
 		chapters = []tables.Chapter{}
 	}
 
-	// 3. Ambil data penulis
-	author, err := c.userDAO.FindUserByID(ctx.Context(), book.AuthorID)
+	// 3. Ambil data publik penulis (tanpa email, telepon, dan rekening bank)
+	author, err := c.userDAO.GetPublicAuthor(ctx.Context(), book.AuthorID)
 	if err != nil || author == nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get author data."})
 	}
-	author.Password = ""
 
 	// 4. Ambil ulasan untuk buku ini
 	reviews, err := c.reviewDAO.GetReviewsByBookID(ctx.Context(), bookId)
@@ -415,9 +471,29 @@ func (c *BookController) GetPublicBookDetail(ctx *fiber.Ctx) error {
 	response := tables.BookDetailResponse{
 		BookInfo: book,
 		Chapters: chapters,
-		Author:   author,
+		Author:   tables.NewBookAuthor(author),
 		Reviews:  reviews,
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(response)
 }
+
+// notifyFollowersOfNewBook mengirim notifikasi buku baru ke semua pengikut penulis.
+// Dijalankan di goroutine terpisah agar respons publikasi tidak menunggu fan-out selesai.
+func (c *BookController) notifyFollowersOfNewBook(authorId, bookId int64, title string) {
+	ctx, cancel := context.WithTimeout(context.Background(), followerFanOutTimeout)
+	defer cancel()
+
+	authorName := "Penulis yang Anda ikuti"
+	if author, err := c.userDAO.FindUserByID(ctx, authorId); err == nil && author != nil && author.PenName != nil {
+		authorName = *author.PenName
+	}
+	content := fmt.Sprintf("%s menerbitkan buku baru: %s", authorName, title)
+
+	sent, err := c.followDAO.NotifyFollowersOfNewBook(ctx, authorId, bookId, content, followerFanOutBatchSize)
+	if err != nil {
+		c.log.WithError(err).Errorf("Fan-out notifikasi buku %d berhenti setelah %d pengikut", bookId, sent)
+		return
+	}
+	c.log.Infof("Notifikasi buku baru %d dikirim ke %d pengikut", bookId, sent)
+}
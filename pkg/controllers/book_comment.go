@@ -16,15 +16,17 @@ type BookCommentController struct {
 	commentDAO *dao.BookCommentDao
 	bookDAO    *dao.BookDao
 	userDAO    *dao.UserDao
+	blockDAO   *dao.UserBlockDao
 	log        *logrus.Logger
 }
 
 // NewBookCommentController membuat instance baru dari BookCommentController.
-func NewBookCommentController(commentDAO *dao.BookCommentDao, bookDAO *dao.BookDao, userDAO *dao.UserDao) *BookCommentController {
+func NewBookCommentController(commentDAO *dao.BookCommentDao, bookDAO *dao.BookDao, userDAO *dao.UserDao, blockDAO *dao.UserBlockDao) *BookCommentController {
 	return &BookCommentController{
 		commentDAO: commentDAO,
 		bookDAO:    bookDAO,
 		userDAO:    userDAO,
+		blockDAO:   blockDAO,
 		log:        logrus.New(),
 	}
 }
@@ -41,7 +43,7 @@ type CreateCommentRequest struct {
 
 // CreateBookComment adalah handler untuk membuat komentar pada sebuah buku.
 // @Summary      Buat Komentar Buku
-// @Description  Membuat komentar baru (atau balasan) pada sebuah buku oleh pengguna terotentikasi.
+// @Description  Membuat komentar baru (atau balasan) pada sebuah buku oleh pengguna terotentikasi. Pengguna yang diblokir tidak bisa membalas komentar milik pemblokirnya.
 // @Tags         Book
 // @Accept       json
 // @Produce      json
@@ -51,6 +53,7 @@ type CreateCommentRequest struct {
 // @Success      201 {object} tables.BookComment
 // @Failure      400 {object} ErrorResponse "Input tidak valid"
 // @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      403 {object} ErrorResponse "Diblokir oleh pemilik komentar yang dibalas"
 // @Failure      404 {object} ErrorResponse "Buku tidak ditemukan"
 // @Failure      500 {object} ErrorResponse "Error internal server"
 // @Router       /v1/books/{bookId}/comments [POST]
@@ -79,6 +82,26 @@ func (c *BookCommentController) CreateBookComment(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Comment text cannot be empty."})
 	}
 
+	// Balasan harus merujuk komentar di buku yang sama, dan pemilik komentar tidak boleh memblokir pembalas
+	if payload.ParentCommentID != nil {
+		parentOwnerId, err := c.commentDAO.GetCommentOwner(ctx.Context(), *payload.ParentCommentID, bookId)
+		if err != nil {
+			c.log.WithError(err).Error("Gagal mengambil komentar induk")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to verify parent comment."})
+		}
+		if parentOwnerId == 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Parent comment not found."})
+		}
+		blocked, err := c.blockDAO.IsBlocked(ctx.Context(), parentOwnerId, actorId)
+		if err != nil {
+			c.log.WithError(err).Error("Gagal memeriksa status blokir")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to verify parent comment."})
+		}
+		if blocked {
+			return ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeUserBlocked, Message: "You cannot reply to this user's comments."})
+		}
+	}
+
 	// 5. Dapatkan data tambahan untuk notifikasi
 	actor, err := c.userDAO.FindUserByID(ctx.Context(), actorId)
 	if err != nil || actor == nil {
@@ -126,7 +149,7 @@ func (c *BookCommentController) CreateBookComment(ctx *fiber.Ctx) error {
 
 // GetBookComments adalah handler untuk mengambil semua komentar pada sebuah buku.
 // @Summary      Dapatkan Komentar Buku
-// @Description  Mengambil daftar semua komentar pada sebuah buku.
+// @Description  Mengambil daftar semua komentar pada sebuah buku. Jika request membawa token, komentar dari pengguna yang diblokir atau dibisukan tidak ditampilkan.
 // @Tags         Book
 // @Produce      json
 // @Param        bookId path int true "ID dari buku"
@@ -140,7 +163,10 @@ func (c *BookCommentController) GetBookComments(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
 	}
 
-	comments, err := c.commentDAO.GetCommentsByBookID(ctx.Context(), bookId)
+	// Locals userId diisi oleh middleware OptionalAuth; tamu (0) melihat semua komentar
+	viewerId, _ := ctx.Locals("userId").(int64)
+
+	comments, err := c.commentDAO.GetCommentsByBookID(ctx.Context(), bookId, viewerId)
 	if err != nil {
 		c.log.WithError(err).Error("Gagal mengambil komentar buku dari DAO")
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve comments."})
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/middleware"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxBookTitleLength = 255
+
+// UpdateBookRequest adalah payload untuk mengubah metadata buku. Field yang tidak dikirim tidak diubah.
+type UpdateBookRequest struct {
+	Title         *string  `json:"title,omitempty" example:"Judul Buku yang Diperbarui"`
+	Description   *string  `json:"description,omitempty" example:"Deskripsi baru. Kirim string kosong untuk menghapus."`
+	CoverImageURL *string  `json:"coverImageUrl,omitempty" example:"https://path.to/new/image.jpg"`
+	GenreIDs      *[]int64 `json:"genreIds,omitempty" example:"1,3"`
+}
+
+// UpdateBookResponse berisi data buku setelah diubah beserta revisi yang tercatat.
+type UpdateBookResponse struct {
+	Book     *tables.Book         `json:"book"`
+	Revision *tables.BookRevision `json:"revision,omitempty"` // Kosong jika tidak ada yang berubah
+}
+
+// UpdateBook mengubah judul, deskripsi, sampul, dan/atau genre sebuah buku.
+// @Summary      Ubah Metadata Buku
+// @Description  Mengubah metadata buku milik penulis yang login. Hanya field yang dikirim yang diubah; kirim string kosong untuk menghapus deskripsi atau sampul. Setiap perubahan dicatat sebagai revisi lengkap dengan data sebelum dan sesudahnya.
+// @Tags         Book Management
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        bookId path int true "ID Buku"
+// @Param        book_data body UpdateBookRequest true "Field metadata yang diubah"
+// @Success      200 {object} UpdateBookResponse
+// @Failure      400 {object} ErrorResponse "Input atau genre tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      403 {object} ErrorResponse "Bukan pemilik buku"
+// @Failure      404 {object} ErrorResponse "Buku tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/{bookId} [PATCH]
+func (c *BookController) UpdateBook(ctx *fiber.Ctx) error {
+	userId, bookId, _, ok, err := c.processBookStatus(ctx)
+	if !ok {
+		return err
+	}
+
+	var payload UpdateBookRequest
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+	if payload.Title == nil && payload.Description == nil && payload.CoverImageURL == nil && payload.GenreIDs == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "At least one of title, description, coverImageUrl or genreIds is required."})
+	}
+
+	update := dao.BookMetadataUpdate{
+		Description:   trimmedOptional(payload.Description),
+		CoverImageURL: trimmedOptional(payload.CoverImageURL),
+	}
+	if payload.Title != nil {
+		title := strings.TrimSpace(*payload.Title)
+		if title == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Title cannot be empty."})
+		}
+		if utf8.RuneCountInString(title) > maxBookTitleLength {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: fmt.Sprintf("Title must be at most %d characters.", maxBookTitleLength)})
+		}
+		update.Title = &title
+	}
+	if payload.GenreIDs != nil {
+		if len(*payload.GenreIDs) == 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "At least one genre ID is required."})
+		}
+		if status, errResp := c.validateActiveGenres(ctx, *payload.GenreIDs); errResp != nil {
+			return ctx.Status(status).JSON(errResp)
+		}
+		update.GenreIDs = *payload.GenreIDs
+	}
+
+	revision, err := c.bookDAO.UpdateBookMetadata(ctx.Context(), bookId, userId, update)
+	if errors.Is(err, dao.ErrBookNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+	}
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengubah metadata buku %d", bookId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to update book."})
+	}
+
+	book, err := c.bookDAO.GetBookDetailByID(ctx.Context(), bookId)
+	if err != nil || book == nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve updated book."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(UpdateBookResponse{Book: book, Revision: revision})
+}
+
+// GetBookRevisions mengambil riwayat perubahan metadata sebuah buku.
+// @Summary      Dapatkan Riwayat Revisi Buku
+// @Description  Mengambil riwayat perubahan metadata buku, terbaru lebih dulu, beserta data sebelum dan sesudah setiap perubahan. Bisa diakses pemilik buku, moderator, dan admin.
+// @Tags         Book Management
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        bookId path int true "ID Buku"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedBookRevisionResponse
+// @Failure      400 {object} ErrorResponse "ID buku tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan pemilik buku atau moderator"
+// @Failure      404 {object} ErrorResponse "Buku tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/{bookId}/revisions [GET]
+func (c *BookController) GetBookRevisions(ctx *fiber.Ctx) error {
+	userId, _ := ctx.Locals("userId").(int64)
+	bookId, err := strconv.ParseInt(ctx.Params("bookId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
+	}
+	book, err := c.bookDAO.GetBookWithAuthor(ctx.Context(), bookId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get book details."})
+	}
+	if book == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+	}
+	if book.AuthorID != userId && !middleware.HasRole(ctx, tables.RoleModerator) && !middleware.HasRole(ctx, tables.RoleAdmin) {
+		return ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeBookNotOwner, Message: "You are not allowed to view this book's revisions."})
+	}
+
+	page, limit, offset := offsetPagination(ctx)
+	revisions, err := c.bookDAO.GetBookRevisions(ctx.Context(), bookId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil revisi buku %d", bookId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve book revisions."})
+	}
+	totalItems, err := c.bookDAO.CountBookRevisions(ctx.Context(), bookId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count book revisions."})
+	}
+
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return ctx.Status(fiber.StatusOK).JSON(tables.PaginatedBookRevisionResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Items:      nonNilSlice(revisions),
+	})
+}
+
+// validateActiveGenres memastikan semua ID genre ada dan masih aktif.
+func (c *BookController) validateActiveGenres(ctx *fiber.Ctx, genreIds []int64) (int, *ErrorResponse) {
+	activeGenres, err := c.genreDAO.GetAllActiveGenres(ctx.Context())
+	if err != nil {
+		c.log.WithError(err).Error("Gagal mengambil daftar genre aktif")
+		return fiber.StatusInternalServerError, &ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to validate genres."}
+	}
+	active := make(map[int64]bool, len(activeGenres))
+	for _, genre := range activeGenres {
+		active[genre.GenreID] = true
+	}
+	for _, genreId := range genreIds {
+		if !active[genreId] {
+			return fiber.StatusBadRequest, &ErrorResponse{Code: constants.ErrCodeGenreInvalid, Message: fmt.Sprintf("Genre ID %d does not exist or is no longer active.", genreId)}
+		}
+	}
+	return 0, nil
+}
+
+// trimmedOptional merapikan field teks opsional dari payload tanpa mengubah nil menjadi string kosong.
+func trimmedOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
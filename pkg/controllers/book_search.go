@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxSearchQueryLength = 100
+
+// publicBookStatuses adalah status buku yang boleh dipakai sebagai filter publik.
+var publicBookStatuses = map[string]bool{tables.BookStatusPublished: true, tables.BookStatusCompleted: true, tables.BookStatusOnHold: true}
+
+// SearchBooks adalah handler publik untuk mencari buku.
+// @Summary      Cari Buku
+// @Description  Mencari buku (kecuali draft) berdasarkan judul, deskripsi, dan nama pena penulis. Judul juga dicocokkan secara fuzzy sehingga salah ketik kecil tetap ditemukan. Hasil dengan kata kunci diurutkan berdasarkan relevansi. genreFacets berisi jumlah hasil per genre tanpa memperhitungkan filter genreIds.
+// @Tags         Book
+// @Produce      json
+// @Param        q query string false "Kata kunci, mendukung tanda kutip untuk frasa dan '-' untuk mengecualikan kata"
+// @Param        genreIds query string false "ID genre dipisah koma, buku cocok jika memiliki salah satunya" example(1,4)
+// @Param        status query string false "Status dipisah koma: P (Published), C (Completed), H (On Hold)" example(P,C)
+// @Param        minRating query number false "Rating rata-rata minimal (0-5)"
+// @Param        completed query bool false "Hanya buku yang sudah tamat (status C)"
+// @Param        free query bool false "Hanya buku yang semua chapter terbitnya gratis"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(10)
+// @Success      200 {object} tables.BookSearchResponse
+// @Failure      400 {object} ErrorResponse "Filter tidak valid"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/search [GET]
+func (c *BookController) SearchBooks(ctx *fiber.Ctx) error {
+	filter, errResp := parseBookListFilter(ctx)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+	filter.Query = strings.TrimSpace(ctx.Query("q"))
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Search query must be at most 100 characters."})
+	}
+
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	books, err := c.bookDAO.SearchBooks(ctx.Context(), filter, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Error("Gagal mencari buku")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to search books."})
+	}
+	totalItems, err := c.bookDAO.CountSearchBooks(ctx.Context(), filter)
+	if err != nil {
+		c.log.WithError(err).Error("Gagal menghitung hasil pencarian buku")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count books."})
+	}
+	facets, err := c.bookDAO.GetSearchGenreFacets(ctx.Context(), filter)
+	if err != nil {
+		c.log.WithError(err).Error("Gagal menghitung facet genre")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count genres."})
+	}
+
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return ctx.Status(fiber.StatusOK).JSON(tables.BookSearchResponse{
+		Pagination:  tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Books:       nonNilSlice(books),
+		GenreFacets: nonNilSlice(facets),
+	})
+}
+
+// parseBookListFilter membaca filter daftar buku dari query string (genreIds, status, minRating,
+// completed, free). Kata kunci pencarian dibaca terpisah oleh SearchBooks.
+func parseBookListFilter(ctx *fiber.Ctx) (dao.BookSearchFilter, *ErrorResponse) {
+	var filter dao.BookSearchFilter
+
+	for _, raw := range splitQueryList(ctx.Query("genreIds")) {
+		genreId, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || genreId <= 0 {
+			return filter, &ErrorResponse{Code: constants.ErrCodeGenreInvalid, Message: "genreIds must be a comma-separated list of genre IDs."}
+		}
+		filter.GenreIDs = append(filter.GenreIDs, genreId)
+	}
+
+	for _, raw := range splitQueryList(ctx.Query("status")) {
+		status := strings.ToUpper(raw)
+		if !publicBookStatuses[status] {
+			return filter, &ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "status must be a comma-separated list of P, C or H."}
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if raw := ctx.Query("minRating"); raw != "" {
+		minRating, err := strconv.ParseFloat(raw, 64)
+		if err != nil || minRating < 0 || minRating > 5 {
+			return filter, &ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "minRating must be a number between 0 and 5."}
+		}
+		filter.MinRating = &minRating
+	}
+
+	completed, err := parseQueryBool(ctx, "completed")
+	if err != nil {
+		return filter, &ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "completed must be true or false."}
+	}
+	if completed {
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, "C") {
+			return filter, &ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "completed=true cannot be combined with a status filter that excludes C."}
+		}
+		filter.Statuses = []string{"C"}
+	}
+
+	if filter.FreeOnly, err = parseQueryBool(ctx, "free"); err != nil {
+		return filter, &ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "free must be true or false."}
+	}
+
+	return filter, nil
+}
+
+// parseQueryBool membaca query string boolean. Parameter yang tidak diisi dianggap false.
+func parseQueryBool(ctx *fiber.Ctx, key string) (bool, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// splitQueryList memecah nilai query string yang dipisah koma dan membuang elemen kosong.
+func splitQueryList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
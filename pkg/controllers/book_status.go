@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/middleware"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxStatusReasonLength = 500
+
+// BookStatusRequest adalah payload opsional untuk endpoint perubahan status buku.
+type BookStatusRequest struct {
+	Reason string `json:"reason,omitempty" example:"Penulis sedang cuti menulis"`
+}
+
+// transitionBookStatus memindahkan status buku lewat tabel transisi di BookDao dan mencatat alasan
+// dari body request (opsional). Jika ok bernilai false, response error sudah dikirim.
+func (c *BookController) transitionBookStatus(ctx *fiber.Ctx, userId, bookId int64, to string) (bool, error) {
+	var payload BookStatusRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&payload); err != nil {
+			return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+		}
+	}
+	reason := strings.TrimSpace(payload.Reason)
+	if utf8.RuneCountInString(reason) > maxStatusReasonLength {
+		return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: fmt.Sprintf("Reason must be at most %d characters.", maxStatusReasonLength)})
+	}
+
+	_, err := c.bookDAO.TransitionBookStatus(ctx.Context(), bookId, userId, to, reason)
+	var transitionErr *dao.BookStatusTransitionError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &transitionErr):
+		return false, ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeBookInvalidStatusTransition, Message: invalidTransitionMessage(transitionErr.From, transitionErr.To)})
+	case errors.Is(err, dao.ErrBookHasNoChapters):
+		return false, ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBookNoChapters, Message: "Cannot publish a book with no published chapters."})
+	case errors.Is(err, dao.ErrBookNotFound):
+		return false, ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+	default:
+		c.log.WithError(err).Errorf("Gagal mengubah status buku %d ke %s", bookId, to)
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeUserUpdateFailed, Message: "Failed to update book status."})
+	}
+}
+
+// invalidTransitionMessage menjelaskan perpindahan yang ditolak beserta status tujuan yang diizinkan.
+func invalidTransitionMessage(from, to string) string {
+	allowed := dao.AllowedBookStatusTransitions(from)
+	labels := make([]string, len(allowed))
+	for i, status := range allowed {
+		labels[i] = tables.BookStatusLabels[status]
+	}
+	message := fmt.Sprintf("Cannot change book status from %s to %s.", tables.BookStatusLabels[from], tables.BookStatusLabels[to])
+	if len(labels) > 0 {
+		message += " Allowed: " + strings.Join(labels, ", ") + "."
+	}
+	return message
+}
+
+// GetBookStatusHistory mengambil riwayat perubahan status sebuah buku.
+// @Summary      Dapatkan Riwayat Status Buku
+// @Description  Mengambil riwayat perpindahan status buku, terbaru lebih dulu, beserta pelaku dan alasannya. Bisa diakses pemilik buku, moderator, dan admin.
+// @Tags         Book Management
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        bookId path int true "ID Buku"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedBookStatusHistoryResponse
+// @Failure      400 {object} ErrorResponse "ID buku tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan pemilik buku atau moderator"
+// @Failure      404 {object} ErrorResponse "Buku tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/{bookId}/status-history [GET]
+func (c *BookController) GetBookStatusHistory(ctx *fiber.Ctx) error {
+	userId, _ := ctx.Locals("userId").(int64)
+	bookId, err := strconv.ParseInt(ctx.Params("bookId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
+	}
+	book, err := c.bookDAO.GetBookWithAuthor(ctx.Context(), bookId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get book details."})
+	}
+	if book == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+	}
+	if book.AuthorID != userId && !middleware.HasRole(ctx, tables.RoleModerator) && !middleware.HasRole(ctx, tables.RoleAdmin) {
+		return ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeBookNotOwner, Message: "You are not allowed to view this book's status history."})
+	}
+
+	page, limit, offset := offsetPagination(ctx)
+	history, err := c.bookDAO.GetBookStatusHistory(ctx.Context(), bookId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil riwayat status buku %d", bookId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve book status history."})
+	}
+	totalItems, err := c.bookDAO.CountBookStatusHistory(ctx.Context(), bookId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count book status history."})
+	}
+
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return ctx.Status(fiber.StatusOK).JSON(tables.PaginatedBookStatusHistoryResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Items:      nonNilSlice(history),
+	})
+}
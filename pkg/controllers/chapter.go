@@ -113,11 +113,64 @@ func (c *ChapterController) CreateChapter(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusCreated).JSON(createdChapter)
 }
 
+// PublishChapter adalah handler untuk menerbitkan chapter draft.
+// @Summary      Terbitkan Chapter
+// @Description  Mengubah status chapter menjadi terbit sehingga tampil ke pembaca. Buku baru bisa diterbitkan setelah memiliki minimal satu chapter terbit. Pengguna harus menjadi pemilik buku.
+// @Tags         Chapter
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        bookId path int true "ID Buku"
+// @Param        chapterId path int true "ID Chapter"
+// @Success      200 {object} tables.Chapter
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      403 {object} ErrorResponse "Akses ditolak (bukan pemilik buku)"
+// @Failure      404 {object} ErrorResponse "Buku atau chapter tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/{bookId}/chapters/{chapterId}/publish [PATCH]
+func (c *ChapterController) PublishChapter(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid user token."})
+	}
+	bookId, err := strconv.ParseInt(ctx.Params("bookId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid book ID."})
+	}
+	chapterId, err := strconv.ParseInt(ctx.Params("chapterId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid chapter ID."})
+	}
+
+	book, err := c.bookDAO.GetBookWithAuthor(ctx.Context(), bookId)
+	if err != nil {
+		c.log.WithError(err).Error("Gagal mengambil detail buku untuk validasi kepemilikan")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to get book details."})
+	}
+	if book == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeBookNotFound, Message: "Book not found."})
+	}
+	if book.AuthorID != userId {
+		return ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{Code: constants.ErrCodeBookNotOwner, Message: "You are not the owner of this book."})
+	}
+
+	chapter, err := c.chapterDAO.PublishChapter(ctx.Context(), bookId, chapterId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal menerbitkan chapter %d", chapterId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to publish chapter."})
+	}
+	if chapter == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeChapterNotFound, Message: "Chapter not found in this book."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(chapter)
+}
+
 // GetChapterContent adalah handler publik untuk membaca isi chapter.
 // @Summary      Dapatkan Isi Chapter (Publik)
-// @Description  Mengambil konten lengkap dari sebuah chapter. Jika chapter berbayar, memerlukan token otentikasi yang valid dan status unlock.
+// @Description  Mengambil konten lengkap dari sebuah chapter. Jika chapter berbayar, memerlukan token otentikasi yang valid dan status unlock. Token bersifat opsional untuk chapter gratis.
 // @Tags         Chapter
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        chapterId path int true "ID Chapter"
 // @Success      200 {object} tables.Chapter
 // @Failure      402 {object} ErrorResponse "Pembayaran/Koin diperlukan"
@@ -144,12 +197,21 @@ func (c *ChapterController) GetChapterContent(ctx *fiber.Ctx) error {
 
 	// --- LOGIKA UNTUK CHAPTER BERBAYAR ---
 	
-	// Coba ambil user_id dari token. Middleware tidak dipakai karena ini endpoint publik.
-	userId, isGuest := GetUserIDFromToken(ctx) // Ini adalah fungsi helper yang perlu kita buat
+	// Ambil user_id dari token jika ada (diisi middleware.OptionalAuth karena ini endpoint publik).
+	userId, isGuest := GetUserIDFromToken(ctx)
 	if isGuest {
 		return ctx.Status(fiber.StatusPaymentRequired).JSON(ErrorResponse{Code: "chapter.error.login_required", Message: "You must be logged in to read a paid chapter."})
 	}
 
+	// Penulis selalu bisa membaca chapter miliknya sendiri tanpa membuka dengan koin
+	book, err := c.bookDAO.GetBookWithAuthor(ctx.Context(), chapter.BookID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve book."})
+	}
+	if book != nil && book.AuthorID == userId {
+		return ctx.Status(fiber.StatusOK).JSON(chapter)
+	}
+
 	// Cek apakah user sudah membuka chapter ini
 	isUnlocked, err := c.chapterDAO.IsChapterUnlockedByUser(ctx.Context(), userId, chapterId)
 	if err != nil {
@@ -164,17 +226,13 @@ func (c *ChapterController) GetChapterContent(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(chapter)
 }
 
-// GetUserIDFromToken adalah helper untuk mengambil user ID dari token JWT secara opsional.
+// GetUserIDFromToken adalah helper untuk mengambil user ID secara opsional di endpoint publik.
+// Nilai userId diisi oleh middleware.OptionalAuth jika request membawa token yang valid;
+// jika tidak ada token, pengguna dianggap tamu.
 func GetUserIDFromToken(c *fiber.Ctx) (userID int64, isGuest bool) {
-    // Implementasi untuk parsing JWT token secara manual dari header 'Authorization'
-    // Mirip dengan yang ada di middleware, tapi tidak mengembalikan error jika token tidak ada.
-    // ...
-    // Jika token ada dan valid, return id, false
-    // Jika tidak ada token, return 0, true
-    // (Untuk saat ini, kita anggap selalu guest jika tidak ada di locals)
 	id, ok := c.Locals("userId").(int64)
 	if !ok || id == 0 {
 		return 0, true
 	}
 	return id, false
-}
\ No newline at end of file
+}
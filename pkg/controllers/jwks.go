@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKS adalah handler untuk mempublikasikan public key penandatangan access token.
+// @Summary JWKS access token
+// @Description Mengembalikan public key (format JWK) yang dipakai untuk memverifikasi access token Nover. Layanan lain memilih key berdasarkan header kid pada token. Key lama tetap tercantum selama masa rotasi. Key yang sama juga menandatangani token sekali pakai, jadi verifier WAJIB memeriksa header typ = at+jwt, claim iss = nover, dan claim aud = nover-api sebelum menerima token sebagai access token.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} jwtkeys.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (c *AuthController) JWKS(ctx *fiber.Ctx) error {
+	// Cache singkat agar key baru cepat terlihat oleh layanan lain saat rotasi
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(c.Keys.JWKS())
+}
@@ -1,10 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"noversystem/pkg/constants"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/dao"
 	"noversystem/pkg/tables"
-	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -30,7 +31,9 @@ func NewNotificationController(notifDAO *dao.NotificationDao) *NotificationContr
 // @Security     ApiKeyAuth
 // @Param        page query int false "Nomor Halaman" default(1)
 // @Param        limit query int false "Jumlah item per halaman" default(10)
+// @Param        cursor query string false "Token nextCursor/prevCursor dari response sebelumnya, menggantikan page"
 // @Success      200 {object} tables.PaginatedNotificationResponse
+// @Failure      400 {object} ErrorResponse "Cursor tidak valid"
 // @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
 // @Failure      500 {object} ErrorResponse "Error internal server"
 // @Router       /v1/notifications [GET]
@@ -40,13 +43,15 @@ func (c *NotificationController) GetNotifications(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
 	}
 
-	page, _ := strconv.Atoi(ctx.Query("page", "1"))
-	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
-	if page < 1 { page = 1 }
-	if limit > 50 { limit = 50 }
-	offset := (page - 1) * limit
+	pageReq, page, errResp := pageRequest(ctx, dao.NotificationCursorKey, 10, 50)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
 
-	notifications, err := c.notifDAO.GetNotificationsByUserID(ctx.Context(), userId, limit, offset)
+	notifications, cursors, err := c.notifDAO.GetNotificationsByUserID(ctx.Context(), userId, pageReq)
+	if errors.Is(err, cursor.ErrInvalid) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeInvalidCursor, Message: "Invalid or expired cursor."})
+	}
 	if err != nil {
 		c.log.WithError(err).Error("Gagal mengambil notifikasi dari DAO")
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve notifications."})
@@ -57,9 +62,8 @@ func (c *NotificationController) GetNotifications(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count notifications."})
 	}
 
-	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
 	response := tables.PaginatedNotificationResponse{
-		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Pagination: newPaginationInfo(ctx, pageReq, page, totalItems, cursors),
 		Items:      notifications,
 	}
 
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/url"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/cursor"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pageRequest membaca parameter page, limit, dan cursor. Jika cursor diisi, page diabaikan dan
+// halaman diambil secara keyset. key adalah nama daftar yang harus cocok dengan isi cursor.
+func pageRequest(ctx *fiber.Ctx, key string, defaultLimit, maxLimit int) (dao.PageRequest, int, *ErrorResponse) {
+	limit, _ := strconv.Atoi(ctx.Query("limit", strconv.Itoa(defaultLimit)))
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	c, err := cursor.Decode(ctx.Query("cursor"), key)
+	if err != nil {
+		return dao.PageRequest{}, 0, &ErrorResponse{Code: constants.ErrCodeInvalidCursor, Message: "Invalid or expired cursor."}
+	}
+	if c != nil {
+		return dao.PageRequest{Limit: limit, Cursor: c}, 0, nil
+	}
+
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	return dao.PageRequest{Limit: limit, Offset: (page - 1) * limit}, page, nil
+}
+
+// offsetPagination membaca parameter page dan limit untuk daftar yang hanya mendukung pagination offset.
+// limit default 20 dan maksimal 100.
+func offsetPagination(ctx *fiber.Ctx) (page, limit, offset int) {
+	page, _ = strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ = strconv.Atoi(ctx.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// newPaginationInfo menyusun info pagination beserta cursor dan link ke halaman berikutnya/sebelumnya.
+// page bernilai 0 jika halaman diambil dengan cursor.
+func newPaginationInfo(ctx *fiber.Ctx, req dao.PageRequest, page int, totalItems int64, cursors cursor.Page) tables.PaginationInfo {
+	totalPages := (totalItems + int64(req.Limit) - 1) / int64(req.Limit)
+	return tables.PaginationInfo{
+		CurrentPage: page,
+		PageSize:    req.Limit,
+		TotalItems:  totalItems,
+		TotalPages:  int(totalPages),
+		NextCursor:  cursors.Next,
+		PrevCursor:  cursors.Prev,
+		Next:        cursorLink(ctx, cursors.Next),
+		Prev:        cursorLink(ctx, cursors.Prev),
+	}
+}
+
+// cursorLink membuat URL request saat ini dengan parameter cursor diganti dan page dibuang.
+func cursorLink(ctx *fiber.Ctx, token string) string {
+	if token == "" {
+		return ""
+	}
+	query := url.Values{}
+	for key, value := range ctx.Queries() {
+		query.Set(key, value)
+	}
+	query.Del("page")
+	query.Set("cursor", token)
+	return ctx.BaseURL() + ctx.Path() + "?" + query.Encode()
+}
+
+// setPaginationHeaders menaruh info pagination di header untuk endpoint yang body-nya tetap berupa
+// array agar klien lama tidak rusak: X-Total-Count, X-Next-Cursor, X-Prev-Cursor, dan Link (RFC 8288).
+func setPaginationHeaders(ctx *fiber.Ctx, info tables.PaginationInfo) {
+	ctx.Set("X-Total-Count", strconv.FormatInt(info.TotalItems, 10))
+
+	var links []string
+	if info.NextCursor != "" {
+		ctx.Set("X-Next-Cursor", info.NextCursor)
+		links = append(links, `<`+info.Next+`>; rel="next"`)
+	}
+	if info.PrevCursor != "" {
+		ctx.Set("X-Prev-Cursor", info.PrevCursor)
+		links = append(links, `<`+info.Prev+`>; rel="prev"`)
+	}
+	if len(links) > 0 {
+		ctx.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/mailer"
+	"noversystem/pkg/tables"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Aturan umum nomor rekening untuk bank yang belum punya aturan panjang sendiri.
+const (
+	defaultAccountNumberMinLength = 5
+	defaultAccountNumberMaxLength = 20
+)
+
+var (
+	accountNumberPattern    = regexp.MustCompile(`^[0-9]+$`)
+	accountNumberSeparators = strings.NewReplacer(" ", "", "-", "", ".", "")
+)
+
+// PayoutAccountController menangani rekening pencairan penulis.
+type PayoutAccountController struct {
+	payoutDAO *dao.PayoutAccountDao
+	bankDAO   *dao.BankDao
+	userDAO   *dao.UserDao
+	mail      mailer.Mailer
+	log       *logrus.Logger
+}
+
+// NewPayoutAccountController membuat instance baru dari PayoutAccountController.
+func NewPayoutAccountController(payoutDAO *dao.PayoutAccountDao, bankDAO *dao.BankDao, userDAO *dao.UserDao, mail mailer.Mailer) *PayoutAccountController {
+	return &PayoutAccountController{
+		payoutDAO: payoutDAO,
+		bankDAO:   bankDAO,
+		userDAO:   userDAO,
+		mail:      mail,
+		log:       logrus.New(),
+	}
+}
+
+// UpdatePayoutAccountPayload adalah body untuk mengganti rekening pencairan.
+type UpdatePayoutAccountPayload struct {
+	BankId        int64  `json:"bankId"`
+	AccountNumber string `json:"accountNumber" example:"1234567890"`
+}
+
+// GetMyPayoutAccount mengembalikan rekening pencairan milik penulis yang sedang login.
+// @Summary      Rekening Pencairan Saya
+// @Description  Mengambil bank dan nomor rekening pencairan. isOnHold bernilai true selama masa tunggu setelah rekening diganti.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200 {object} tables.PayoutAccount
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Belum ada rekening pencairan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/payout-account [GET]
+func (c *PayoutAccountController) GetMyPayoutAccount(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	account, err := c.payoutDAO.GetPayoutAccount(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil rekening pencairan user %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve payout account."})
+	}
+	if account == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodePayoutAccountNotFound, Message: "No payout account has been set."})
+	}
+	return ctx.JSON(account)
+}
+
+// UpdateMyPayoutAccount mengganti rekening pencairan penulis.
+// @Summary      Ganti Rekening Pencairan
+// @Description  Mengganti bank dan nomor rekening pencairan. Bank harus aktif dan nomor rekening harus sesuai format bank tersebut. Jika sebelumnya sudah ada rekening, pencairan ke rekening baru ditahan selama masa tunggu (PAYOUT_ACCOUNT_COOLING_OFF_HOURS) dan email pemberitahuan dikirim ke pemilik akun. Pengguna dengan 2FA harus memverifikasi ulang lewat /auth/2fa/verify.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        account body UpdatePayoutAccountPayload true "Rekening pencairan baru"
+// @Success      200 {object} tables.PayoutAccountChange
+// @Failure      400 {object} ErrorResponse "Bank atau nomor rekening tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      403 {object} ErrorResponse "Bukan penulis atau perlu verifikasi 2FA ulang"
+// @Failure      409 {object} ErrorResponse "Rekening tidak berubah"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/payout-account [PUT]
+func (c *PayoutAccountController) UpdateMyPayoutAccount(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var payload UpdatePayoutAccountPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+	bank, accountNumber, status, errResp := validatePayoutAccount(ctx.Context(), c.log, c.bankDAO, payload.BankId, payload.AccountNumber)
+	if errResp != nil {
+		return ctx.Status(status).JSON(errResp)
+	}
+
+	change := &tables.PayoutAccountChange{
+		UserID:           userId,
+		NewBankID:        bank.BankId,
+		NewBankName:      bank.BankName,
+		NewAccountNumber: accountNumber,
+		Source:           tables.PayoutAccountSourceUser,
+		IPAddress:        ctx.IP(),
+		UserAgent:        ctx.Get(fiber.HeaderUserAgent),
+	}
+	if err := c.payoutDAO.ChangePayoutAccount(ctx.Context(), change, config.Cfg.Payout.CoolingOff); err != nil {
+		switch {
+		case errors.Is(err, dao.ErrPayoutAccountUnchanged):
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodePayoutAccountUnchanged, Message: "This is already your payout account."})
+		case errors.Is(err, dao.ErrUserNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+		}
+		c.log.WithError(err).Errorf("Gagal mengganti rekening pencairan user %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to update payout account."})
+	}
+
+	// Pemberitahuan hanya perlu saat rekening lama diganti, karena itulah yang berbahaya jika akun dibajak
+	if change.OldBankID != nil {
+		if err := c.sendPayoutAccountChangedEmail(ctx.Context(), change); err != nil {
+			c.log.WithError(err).Warnf("Gagal mengirim email perubahan rekening pencairan user %d", userId)
+		}
+	}
+
+	return ctx.JSON(change)
+}
+
+// GetMyPayoutAccountHistory mengembalikan riwayat perubahan rekening pencairan.
+// @Summary      Riwayat Rekening Pencairan
+// @Description  Mengambil riwayat perubahan rekening pencairan, yang terbaru lebih dulu.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedPayoutAccountChangeResponse
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/payout-account/history [GET]
+func (c *PayoutAccountController) GetMyPayoutAccountHistory(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	page, limit, offset := offsetPagination(ctx)
+
+	changes, err := c.payoutDAO.GetChanges(ctx.Context(), userId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil riwayat rekening pencairan user %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve payout account history."})
+	}
+	totalItems, err := c.payoutDAO.CountChanges(ctx.Context(), userId)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count payout account history."})
+	}
+
+	return ctx.JSON(tables.PaginatedPayoutAccountChangeResponse{
+		Pagination: tables.PaginationInfo{
+			CurrentPage: page,
+			PageSize:    limit,
+			TotalItems:  totalItems,
+			TotalPages:  int((totalItems + int64(limit) - 1) / int64(limit)),
+		},
+		Items: nonNilSlice(changes),
+	})
+}
+
+// sendPayoutAccountChangedEmail memberi tahu pemilik akun bahwa rekening pencairan diganti.
+func (c *PayoutAccountController) sendPayoutAccountChangedEmail(ctx context.Context, change *tables.PayoutAccountChange) error {
+	user, err := c.userDAO.FindUserByID(ctx, change.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nRekening pencairan akun Anda baru saja diganti ke rekening berakhiran %s. Pencairan ke rekening baru ditahan sampai %s.\n\nJika Anda tidak melakukan perubahan ini, segera ganti password dan hubungi tim %s.\n",
+		displayName(user), lastDigits(change.NewAccountNumber, 4), change.EffectiveDatetime.Format("02 Jan 2006 15:04 MST"), config.Cfg.App.Name,
+	)
+	return c.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Rekening pencairan " + config.Cfg.App.Name + " diganti",
+		Body:    body,
+	})
+}
+
+// validatePayoutAccount memastikan bank masih aktif dan nomor rekening sesuai aturan panjang bank tersebut.
+// Mengembalikan bank beserta nomor rekening yang sudah dibersihkan dari spasi, tanda hubung, dan titik,
+// atau status HTTP dan error response jika tidak valid.
+func validatePayoutAccount(ctx context.Context, log *logrus.Logger, bankDAO *dao.BankDao, bankId int64, rawAccountNumber string) (*tables.Bank, string, int, *ErrorResponse) {
+	accountNumber := accountNumberSeparators.Replace(strings.TrimSpace(rawAccountNumber))
+	if bankId <= 0 || accountNumber == "" {
+		return nil, "", fiber.StatusBadRequest, &ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Bank and account number are required."}
+	}
+
+	bank, err := bankDAO.GetActiveBank(ctx, bankId)
+	if err != nil {
+		log.WithError(err).Errorf("Gagal memeriksa bank %d", bankId)
+		return nil, "", fiber.StatusInternalServerError, &ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to check bank."}
+	}
+	if bank == nil {
+		return nil, "", fiber.StatusBadRequest, &ErrorResponse{Code: constants.ErrCodeBankInvalid, Message: "The selected bank is not available."}
+	}
+
+	minLength, maxLength := defaultAccountNumberMinLength, defaultAccountNumberMaxLength
+	if bank.AccountNumberMinLength != nil {
+		minLength = *bank.AccountNumberMinLength
+	}
+	if bank.AccountNumberMaxLength != nil {
+		maxLength = *bank.AccountNumberMaxLength
+	}
+	if !accountNumberPattern.MatchString(accountNumber) || len(accountNumber) < minLength || len(accountNumber) > maxLength {
+		lengthRule := fmt.Sprintf("%d to %d digits", minLength, maxLength)
+		if minLength == maxLength {
+			lengthRule = fmt.Sprintf("%d digits", minLength)
+		}
+		return nil, "", fiber.StatusBadRequest, &ErrorResponse{Code: constants.ErrCodeBankAccountInvalid, Message: fmt.Sprintf("Account numbers for %s must be %s.", bank.BankName, lengthRule)}
+	}
+	return bank, accountNumber, 0, nil
+}
+
+// lastDigits mengembalikan n karakter terakhir, dipakai agar nomor rekening lengkap tidak muncul di email.
+func lastDigits(value string, n int) string {
+	if len(value) <= n {
+		return value
+	}
+	return value[len(value)-n:]
+}
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/sms"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// PhoneVerificationController menangani verifikasi nomor telepon lewat OTP.
+type PhoneVerificationController struct {
+	verificationDAO *dao.PhoneVerificationDao
+	userDAO         *dao.UserDao
+	sms             sms.Provider
+	log             *logrus.Logger
+}
+
+// NewPhoneVerificationController membuat instance baru dari PhoneVerificationController.
+func NewPhoneVerificationController(verificationDAO *dao.PhoneVerificationDao, userDAO *dao.UserDao, smsProvider sms.Provider) *PhoneVerificationController {
+	return &PhoneVerificationController{
+		verificationDAO: verificationDAO,
+		userDAO:         userDAO,
+		sms:             smsProvider,
+		log:             logrus.New(),
+	}
+}
+
+// SendPhoneOTPPayload adalah body untuk meminta kode OTP.
+type SendPhoneOTPPayload struct {
+	Phone   string `json:"phone" example:"+6281234567890"` // Kosongkan untuk memverifikasi nomor yang sudah ada di profil
+	Channel string `json:"channel" example:"sms"`          // "sms" (default) atau "whatsapp"
+}
+
+// VerifyPhoneOTPPayload adalah body untuk memverifikasi kode OTP.
+type VerifyPhoneOTPPayload struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// SendPhoneOTPResponse adalah response setelah OTP dikirim.
+type SendPhoneOTPResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Phone     string `json:"phone"`
+	Channel   string `json:"channel"`
+	ExpiresIn int64  `json:"expiresIn"` // Masa berlaku OTP dalam detik
+}
+
+// SendPhoneOTP mengirim kode OTP ke nomor telepon pengguna.
+// @Summary      Kirim OTP verifikasi nomor telepon
+// @Description  Mengirim kode 6 digit lewat SMS atau WhatsApp. Jika phone dikirim, nomor tersebut baru disimpan ke profil setelah kode diverifikasi. Pengiriman dibatasi per pengguna dan per nomor, lihat header Retry-After saat 429.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        otp body SendPhoneOTPPayload false "Nomor tujuan dan kanal"
+// @Success      202 {object} SendPhoneOTPResponse
+// @Failure      400 {object} ErrorResponse "Nomor tidak valid atau belum ada di profil"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      409 {object} ErrorResponse "Nomor sudah terverifikasi atau dipakai akun lain"
+// @Failure      429 {object} ErrorResponse "Terlalu sering meminta OTP"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/phone/otp [POST]
+func (c *PhoneVerificationController) SendPhoneOTP(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var payload SendPhoneOTPPayload
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&payload); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+		}
+	}
+
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to find user by ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve user data."})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+	}
+
+	// 1. Tentukan nomor dan kanal tujuan
+	phone := phoneSeparators.Replace(strings.TrimSpace(payload.Phone))
+	if phone == "" && user.Phone != nil {
+		phone = *user.Phone
+	}
+	if phone == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Phone number is required."})
+	}
+	if !phonePattern.MatchString(phone) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Phone number must contain 8 to 15 digits, optionally starting with '+'."})
+	}
+	channel := sms.Channel(strings.ToLower(strings.TrimSpace(payload.Channel)))
+	if channel == "" {
+		channel = sms.ChannelSMS
+	}
+	if channel != sms.ChannelSMS && channel != sms.ChannelWhatsApp {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Channel must be 'sms' or 'whatsapp'."})
+	}
+
+	if user.IsPhoneVerified && user.Phone != nil && *user.Phone == phone {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodePhoneAlreadyVerified, Message: "This phone number is already verified."})
+	}
+	isTaken, err := c.userDAO.IsPhoneTakenByOther(ctx.Context(), phone, userId)
+	if err != nil {
+		c.log.WithError(err).Error("Failed to check phone availability")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "A server error occurred while checking phone number."})
+	}
+	if isTaken {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "This phone number is already used by another account."})
+	}
+
+	// 2. Buat OTP lalu simpan jika rate limit mengizinkan: jeda antar pengiriman dan batas per jam
+	// (per pengguna dan per nomor), diperiksa di DAO secara atomik bersama penyimpanannya
+	now := time.Now()
+	code, err := generateOTP()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to generate code."})
+	}
+	verification := &tables.PhoneVerification{
+		UserID:          userId,
+		Phone:           phone,
+		Channel:         string(channel),
+		CodeHash:        hashToken(code),
+		ExpiresDatetime: now.Add(config.Cfg.Phone.OTPTTL),
+	}
+	err = c.verificationDAO.CreateVerification(ctx.Context(), verification, now.Add(-time.Hour), func(stats *dao.PhoneSendStats) error {
+		return checkOTPSendRate(stats, now)
+	})
+	var throttled *otpThrottledError
+	if errors.As(err, &throttled) {
+		return respondOTPThrottled(ctx, throttled.wait, throttled.message)
+	}
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to store phone OTP for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	// 3. Kirim OTP
+	message := sms.Message{
+		To:      phone,
+		Channel: channel,
+		Body: fmt.Sprintf("Kode verifikasi Nover kamu: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.",
+			code, int(config.Cfg.Phone.OTPTTL.Minutes())),
+	}
+	if err := c.sms.Send(ctx.Context(), message); err != nil {
+		c.log.WithError(err).Errorf("Failed to send phone OTP for user ID %d", userId)
+		return ctx.Status(fiber.StatusBadGateway).JSON(ErrorResponse{Code: constants.ErrCodePhoneOTPDelivery, Message: "Failed to send verification code, please try again."})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(SendPhoneOTPResponse{
+		Code:      "user.phone.otp_sent",
+		Message:   "Verification code sent.",
+		Phone:     phone,
+		Channel:   string(channel),
+		ExpiresIn: int64(config.Cfg.Phone.OTPTTL.Seconds()),
+	})
+}
+
+// VerifyPhoneOTP memverifikasi kode OTP dan menandai nomor telepon sebagai terverifikasi.
+// @Summary      Verifikasi OTP nomor telepon
+// @Description  Mencocokkan kode OTP terakhir yang dikirim. Jika cocok, nomor tersebut disimpan ke profil sebagai nomor terverifikasi. Setiap kode hanya bisa dicoba beberapa kali.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        otp body VerifyPhoneOTPPayload true "Kode OTP"
+// @Success      200 {object} UserProfileResponse
+// @Failure      400 {object} ErrorResponse "Kode salah, kedaluwarsa, atau tidak ada OTP yang aktif"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      409 {object} ErrorResponse "Nomor sudah dipakai akun lain"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/phone/verify [POST]
+func (c *PhoneVerificationController) VerifyPhoneOTP(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	var payload VerifyPhoneOTPPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+	code := strings.TrimSpace(payload.Code)
+	if code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "Code is required."})
+	}
+
+	verification, err := c.verificationDAO.GetPendingVerification(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to get phone OTP for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if verification == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodePhoneOTPExpired, Message: "No active verification code, please request a new one."})
+	}
+
+	// Percobaan dicatat sebelum kode dicocokkan agar batas percobaan tidak bisa dilewati dengan request paralel
+	if err := c.verificationDAO.ConsumeAttempt(ctx.Context(), verification.VerificationID, config.Cfg.Phone.MaxAttempts); err != nil {
+		if errors.Is(err, dao.ErrPhoneVerificationNotFound) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodePhoneOTPExpired, Message: "This code has expired or was tried too many times, please request a new one."})
+		}
+		c.log.WithError(err).Errorf("Failed to record phone OTP attempt for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(verification.CodeHash)) != 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodePhoneOTPInvalid, Message: "Invalid verification code."})
+	}
+
+	if err := c.verificationDAO.CompleteVerification(ctx.Context(), verification.VerificationID, userId, verification.Phone); err != nil {
+		switch {
+		case errors.Is(err, dao.ErrPhoneTaken):
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "This phone number is already used by another account."})
+		case errors.Is(err, dao.ErrPhoneVerificationNotFound):
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodePhoneOTPExpired, Message: "This code has already been used."})
+		}
+		c.log.WithError(err).Errorf("Failed to complete phone verification for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Database error"})
+	}
+
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil || user == nil {
+		c.log.WithError(err).Errorf("Failed to find user by ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve user data."})
+	}
+	return ctx.JSON(newUserProfileResponse(user))
+}
+
+// otpThrottledError menandai permintaan OTP yang ditolak rate limit beserta waktu tunggunya.
+type otpThrottledError struct {
+	wait    time.Duration
+	message string
+}
+
+func (e *otpThrottledError) Error() string {
+	return e.message
+}
+
+// checkOTPSendRate menolak pengiriman OTP yang terlalu cepat dari pengiriman sebelumnya atau
+// melewati batas per jam, per pengguna maupun per nomor.
+func checkOTPSendRate(stats *dao.PhoneSendStats, now time.Time) error {
+	if stats.LastSentAt != nil {
+		if wait := stats.LastSentAt.Add(config.Cfg.Phone.ResendInterval).Sub(now); wait > 0 {
+			return &otpThrottledError{wait: wait, message: "Please wait before requesting another code."}
+		}
+	}
+	if (stats.UserSends >= config.Cfg.Phone.MaxSendsPerHour || stats.PhoneSends >= config.Cfg.Phone.MaxSendsPerHour) && stats.OldestSentAt != nil {
+		return &otpThrottledError{wait: stats.OldestSentAt.Add(time.Hour).Sub(now), message: "Too many verification codes requested, try again later."}
+	}
+	return nil
+}
+
+// respondOTPThrottled mengirim 429 beserta header Retry-After dalam detik.
+func respondOTPThrottled(ctx *fiber.Ctx, wait time.Duration, message string) error {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return ctx.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{Code: constants.ErrCodeAuthTooManyAttempts, Message: message})
+}
+
+// generateOTP membuat kode angka 6 digit yang acak secara kriptografis.
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	"noversystem/pkg/constants"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/dao"
 	"noversystem/pkg/tables"
 
@@ -20,24 +22,45 @@ func NewTransactionController(txDAO *dao.TransactionDao) *TransactionController
 
 // GetMyTransactions mengambil riwayat transaksi pengguna.
 // @Summary      Dapatkan Riwayat Transaksi
+// @Description  Mengambil riwayat transaksi koin per halaman. Body tetap berupa array transaksi; cursor halaman berikutnya/sebelumnya dikirim lewat header X-Next-Cursor, X-Prev-Cursor, dan Link.
 // @Tags         Wallet
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        type query string true "Tipe transaksi ('earn' atau 'spend')"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(50)
+// @Param        cursor query string false "Token dari header X-Next-Cursor/X-Prev-Cursor response sebelumnya, menggantikan page"
 // @Success      200 {object} []tables.CoinTransaction
+// @Header       200 {integer} X-Total-Count "Jumlah seluruh transaksi"
+// @Header       200 {string} X-Next-Cursor "Cursor halaman berikutnya, kosong jika sudah halaman terakhir"
+// @Header       200 {string} X-Prev-Cursor "Cursor halaman sebelumnya"
+// @Header       200 {string} Link "URL halaman berikutnya/sebelumnya (rel=next, rel=prev)"
+// @Failure      400 {object} ErrorResponse "Cursor tidak valid"
 // @Router       /v1/wallet/transactions [GET]
 func (c *TransactionController) GetMyTransactions(ctx *fiber.Ctx) error {
 	userId, _ := ctx.Locals("userId").(int64)
 	txType := ctx.Query("type", "earn") // default 'earn'
 	isDebit := txType == "spend"
 
-	// Di sini Anda bisa menambahkan pagination jika diperlukan
-	transactions, err := c.txDAO.GetTransactionsByUserID(ctx.Context(), userId, isDebit, 50, 0) // Limit 50
+	pageReq, page, errResp := pageRequest(ctx, dao.TransactionCursorKey(isDebit), 50, 100)
+	if errResp != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(errResp)
+	}
+
+	transactions, cursors, err := c.txDAO.GetTransactionsByUserID(ctx.Context(), userId, isDebit, pageReq)
+	if errors.Is(err, cursor.ErrInvalid) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeInvalidCursor, Message: "Invalid or expired cursor."})
+	}
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve transactions."})
 	}
+	totalItems, err := c.txDAO.CountTransactionsByUserID(ctx.Context(), userId, isDebit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count transactions."})
+	}
 	if transactions == nil {
 		transactions = make([]tables.CoinTransaction, 0)
 	}
+	setPaginationHeaders(ctx, newPaginationInfo(ctx, pageReq, page, totalItems, cursors))
 	return ctx.Status(fiber.StatusOK).JSON(transactions)
 }
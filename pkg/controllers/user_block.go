@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// UserBlockController menangani fitur memblokir dan membisukan pengguna lain.
+type UserBlockController struct {
+	blockDAO *dao.UserBlockDao
+	log      *logrus.Logger
+}
+
+func NewUserBlockController(blockDAO *dao.UserBlockDao) *UserBlockController {
+	return &UserBlockController{
+		blockDAO: blockDAO,
+		log:      logrus.New(),
+	}
+}
+
+// BlockUser adalah handler untuk memblokir pengguna.
+// @Summary      Blokir Pengguna
+// @Description  Memblokir pengguna: komentar dan notifikasi darinya disembunyikan, dan ia tidak bisa membalas komentar Anda. Pengguna yang sedang dibisukan akan berubah menjadi diblokir.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid atau memblokir diri sendiri"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/users/{userId}/block [POST]
+func (c *UserBlockController) BlockUser(ctx *fiber.Ctx) error {
+	return c.addRelation(ctx, tables.BlockTypeBlock)
+}
+
+// UnblockUser adalah handler untuk membatalkan blokir.
+// @Summary      Batalkan Blokir
+// @Description  Membatalkan blokir pada pengguna. Notifikasi lama darinya akan tampil kembali.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak sedang diblokir"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/users/{userId}/block [DELETE]
+func (c *UserBlockController) UnblockUser(ctx *fiber.Ctx) error {
+	return c.removeRelation(ctx, tables.BlockTypeBlock)
+}
+
+// MuteUser adalah handler untuk membisukan pengguna.
+// @Summary      Bisukan Pengguna
+// @Description  Membisukan pengguna: komentar dan notifikasi darinya disembunyikan, tetapi ia masih bisa membalas komentar Anda. Tidak mengubah apa pun jika pengguna sudah diblokir.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid atau membisukan diri sendiri"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/users/{userId}/mute [POST]
+func (c *UserBlockController) MuteUser(ctx *fiber.Ctx) error {
+	return c.addRelation(ctx, tables.BlockTypeMute)
+}
+
+// UnmuteUser adalah handler untuk membatalkan bisukan.
+// @Summary      Batalkan Bisukan
+// @Description  Membatalkan bisukan pada pengguna.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak sedang dibisukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/users/{userId}/mute [DELETE]
+func (c *UserBlockController) UnmuteUser(ctx *fiber.Ctx) error {
+	return c.removeRelation(ctx, tables.BlockTypeMute)
+}
+
+// GetMyBlockedUsers adalah handler untuk melihat pengguna yang diblokir atau dibisukan.
+// @Summary      Daftar Blokir Saya
+// @Description  Mengambil daftar pengguna yang diblokir atau dibisukan, yang terbaru lebih dulu. Filter dengan type=BLOCK atau type=MUTE.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        type query string false "BLOCK atau MUTE"
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(20)
+// @Success      200 {object} tables.PaginatedBlockedUserResponse
+// @Failure      400 {object} ErrorResponse "Tipe tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/blocks [GET]
+func (c *UserBlockController) GetMyBlockedUsers(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	blockType := strings.ToUpper(ctx.Query("type"))
+	if blockType != "" && blockType != tables.BlockTypeBlock && blockType != tables.BlockTypeMute {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Type must be BLOCK or MUTE."})
+	}
+	page, limit, offset := offsetPagination(ctx)
+
+	users, err := c.blockDAO.ListBlocked(ctx.Context(), userId, blockType, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil daftar blokir user %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve blocked users."})
+	}
+	totalItems, err := c.blockDAO.CountBlocked(ctx.Context(), userId, blockType)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count blocked users."})
+	}
+
+	if users == nil {
+		users = make([]tables.BlockedUser, 0)
+	}
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return ctx.Status(fiber.StatusOK).JSON(tables.PaginatedBlockedUserResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Items:      users,
+	})
+}
+
+func (c *UserBlockController) addRelation(ctx *fiber.Ctx, blockType string) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	targetId, err := strconv.ParseInt(ctx.Params("userId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid user ID."})
+	}
+	if targetId == userId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "You cannot block or mute yourself."})
+	}
+
+	if blockType == tables.BlockTypeBlock {
+		err = c.blockDAO.Block(ctx.Context(), userId, targetId)
+	} else {
+		err = c.blockDAO.Mute(ctx.Context(), userId, targetId)
+	}
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+		}
+		c.log.WithError(err).Errorf("User %d gagal menyimpan relasi %s ke user %d", userId, blockType, targetId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to update block list."})
+	}
+
+	if blockType == tables.BlockTypeBlock {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "user.block.success", "message": "User blocked."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "user.mute.success", "message": "User muted."})
+}
+
+func (c *UserBlockController) removeRelation(ctx *fiber.Ctx, blockType string) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	targetId, err := strconv.ParseInt(ctx.Params("userId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid user ID."})
+	}
+
+	removed, err := c.blockDAO.Remove(ctx.Context(), userId, targetId, blockType)
+	if err != nil {
+		c.log.WithError(err).Errorf("User %d gagal menghapus relasi %s ke user %d", userId, blockType, targetId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to update block list."})
+	}
+
+	if blockType == tables.BlockTypeBlock {
+		if !removed {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "This user is not blocked."})
+		}
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "user.unblock.success", "message": "User unblocked."})
+	}
+	if !removed {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "This user is not muted."})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "user.unmute.success", "message": "User unmuted."})
+}
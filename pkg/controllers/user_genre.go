@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// maxFavoriteGenres membatasi jumlah genre favorit agar rekomendasi tetap relevan.
+const maxFavoriteGenres = 10
+
+// UserGenreController menangani genre favorit pengguna dan rekomendasi buku berdasarkan genre tersebut.
+type UserGenreController struct {
+	genreDAO     *dao.GenreDao
+	userGenreDAO *dao.UserGenreDao
+	bookDAO      *dao.BookDao
+	log          *logrus.Logger
+}
+
+func NewUserGenreController(genreDAO *dao.GenreDao, userGenreDAO *dao.UserGenreDao, bookDAO *dao.BookDao) *UserGenreController {
+	return &UserGenreController{
+		genreDAO:     genreDAO,
+		userGenreDAO: userGenreDAO,
+		bookDAO:      bookDAO,
+		log:          logrus.New(),
+	}
+}
+
+// FavoriteGenresPayload adalah body untuk mengganti atau menambah genre favorit.
+type FavoriteGenresPayload struct {
+	GenreIDs []int64 `json:"genreIds" example:"1,2,3"`
+}
+
+// GetMyGenres adalah handler untuk melihat genre favorit pengguna yang sedang login.
+// @Summary      Genre Favorit Saya
+// @Description  Mengambil daftar genre favorit pengguna. Genre yang sudah tidak aktif tidak ditampilkan.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200 {object} GenreListResponse
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/genres [GET]
+func (c *UserGenreController) GetMyGenres(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+	return c.respondWithGenres(ctx, userId)
+}
+
+// SetMyGenres adalah handler untuk mengganti seluruh genre favorit, dipakai saat onboarding.
+// @Summary      Atur Genre Favorit
+// @Description  Mengganti seluruh genre favorit dengan daftar baru. Daftar kosong menghapus semua genre favorit.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        genres body FavoriteGenresPayload true "ID genre favorit"
+// @Success      200 {object} GenreListResponse
+// @Failure      400 {object} ErrorResponse "Genre tidak valid atau melebihi batas"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/genres [PUT]
+func (c *UserGenreController) SetMyGenres(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	genreIds, errResp := c.parseGenrePayload(ctx, true)
+	if errResp != nil {
+		return ctx.Status(errResp.status).JSON(errResp.body)
+	}
+	if len(genreIds) > maxFavoriteGenres {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeGenreLimitExceeded, Message: fmt.Sprintf("You can choose at most %d favorite genres.", maxFavoriteGenres)})
+	}
+
+	if err := c.userGenreDAO.SetUserGenres(ctx.Context(), userId, genreIds); err != nil {
+		c.log.WithError(err).Errorf("Gagal mengatur genre favorit user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to save favorite genres."})
+	}
+	return c.respondWithGenres(ctx, userId)
+}
+
+// AddMyGenres adalah handler untuk menambah genre favorit tanpa menghapus yang sudah ada.
+// @Summary      Tambah Genre Favorit
+// @Description  Menambahkan satu atau lebih genre ke daftar favorit. Genre yang sudah menjadi favorit diabaikan.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        genres body FavoriteGenresPayload true "ID genre yang ditambahkan"
+// @Success      200 {object} GenreListResponse
+// @Failure      400 {object} ErrorResponse "Genre tidak valid atau melebihi batas"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/genres [POST]
+func (c *UserGenreController) AddMyGenres(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	genreIds, errResp := c.parseGenrePayload(ctx, false)
+	if errResp != nil {
+		return ctx.Status(errResp.status).JSON(errResp.body)
+	}
+
+	if err := c.userGenreDAO.AddUserGenres(ctx.Context(), userId, genreIds, maxFavoriteGenres); err != nil {
+		if errors.Is(err, dao.ErrTooManyGenres) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeGenreLimitExceeded, Message: fmt.Sprintf("You can choose at most %d favorite genres.", maxFavoriteGenres)})
+		}
+		c.log.WithError(err).Errorf("Gagal menambah genre favorit user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to save favorite genres."})
+	}
+	return c.respondWithGenres(ctx, userId)
+}
+
+// RemoveMyGenre adalah handler untuk menghapus satu genre dari daftar favorit.
+// @Summary      Hapus Genre Favorit
+// @Description  Menghapus satu genre dari daftar favorit pengguna.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        genreId path int true "ID Genre"
+// @Success      200 {object} GenreListResponse
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Genre bukan favorit pengguna"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me/genres/{genreId} [DELETE]
+func (c *UserGenreController) RemoveMyGenre(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	genreId, err := strconv.ParseInt(ctx.Params("genreId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid genre ID."})
+	}
+
+	removed, err := c.userGenreDAO.RemoveUserGenre(ctx.Context(), userId, genreId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal menghapus genre favorit user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to remove favorite genre."})
+	}
+	if !removed {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeGenreNotFavorite, Message: "This genre is not in your favorites."})
+	}
+	return c.respondWithGenres(ctx, userId)
+}
+
+// GetRecommendedBooks adalah handler untuk daftar buku rekomendasi di beranda.
+// @Summary      Rekomendasi Buku
+// @Description  Mengambil buku yang sudah dipublikasikan, diurutkan dari yang paling banyak cocok dengan genre favorit pengguna. Tanpa genre favorit, hasilnya buku terpopuler.
+// @Tags         Book
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        page query int false "Nomor Halaman" default(1)
+// @Param        limit query int false "Jumlah item per halaman" default(10)
+// @Success      200 {object} tables.PaginatedBookResponse
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/books/recommended [GET]
+func (c *UserGenreController) GetRecommendedBooks(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	page, _ := strconv.Atoi(ctx.Query("page", "1"))
+	limit, _ := strconv.Atoi(ctx.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	books, err := c.bookDAO.GetRecommendedBooks(ctx.Context(), userId, limit, offset)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil rekomendasi buku untuk user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve recommended books."})
+	}
+	totalItems, err := c.bookDAO.CountPublishedBooks(ctx.Context(), dao.BookSearchFilter{})
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to count books."})
+	}
+	if books == nil {
+		books = make([]tables.Book, 0)
+	}
+
+	totalPages := (totalItems + int64(limit) - 1) / int64(limit)
+	return ctx.Status(fiber.StatusOK).JSON(tables.PaginatedBookResponse{
+		Pagination: tables.PaginationInfo{CurrentPage: page, PageSize: limit, TotalItems: totalItems, TotalPages: int(totalPages)},
+		Books:      books,
+	})
+}
+
+type genrePayloadError struct {
+	status int
+	body   ErrorResponse
+}
+
+// parseGenrePayload membaca daftar ID genre, membuang duplikat, dan memastikan semuanya
+// adalah genre aktif. allowEmpty mengizinkan daftar kosong (untuk menghapus semua favorit).
+func (c *UserGenreController) parseGenrePayload(ctx *fiber.Ctx, allowEmpty bool) ([]int64, *genrePayloadError) {
+	var payload FavoriteGenresPayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return nil, &genrePayloadError{fiber.StatusBadRequest, ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."}}
+	}
+	if len(payload.GenreIDs) == 0 && !allowEmpty {
+		return nil, &genrePayloadError{fiber.StatusBadRequest, ErrorResponse{Code: constants.ErrCodeAuthInputRequired, Message: "At least one genre ID is required."}}
+	}
+
+	activeGenres, err := c.genreDAO.GetAllActiveGenres(ctx.Context())
+	if err != nil {
+		c.log.WithError(err).Error("Gagal mengambil daftar genre aktif")
+		return nil, &genrePayloadError{fiber.StatusInternalServerError, ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to validate genres."}}
+	}
+	active := make(map[int64]bool, len(activeGenres))
+	for _, genre := range activeGenres {
+		active[genre.GenreID] = true
+	}
+
+	genreIds := make([]int64, 0, len(payload.GenreIDs))
+	seen := make(map[int64]bool, len(payload.GenreIDs))
+	for _, genreId := range payload.GenreIDs {
+		if !active[genreId] {
+			return nil, &genrePayloadError{fiber.StatusBadRequest, ErrorResponse{Code: constants.ErrCodeGenreInvalid, Message: fmt.Sprintf("Genre ID %d does not exist or is no longer active.", genreId)}}
+		}
+		if !seen[genreId] {
+			seen[genreId] = true
+			genreIds = append(genreIds, genreId)
+		}
+	}
+	return genreIds, nil
+}
+
+func (c *UserGenreController) respondWithGenres(ctx *fiber.Ctx, userId int64) error {
+	genres, err := c.userGenreDAO.GetGenresByUserID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil genre favorit user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve favorite genres."})
+	}
+	if genres == nil {
+		genres = []tables.Genre{}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(GenreListResponse{GenreList: genres})
+}
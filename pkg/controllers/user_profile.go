@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"errors"
+	"net/url"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxBioLength = 500
+
+var (
+	instagramPattern = regexp.MustCompile(`^[A-Za-z0-9._]{1,30}$`)
+	phonePattern     = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+	phoneSeparators  = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+)
+
+// UserProfileResponse adalah data profil milik pengguna yang sedang login.
+type UserProfileResponse struct {
+	UserId          int64      `json:"userId"`
+	Email           string     `json:"email"`
+	FullName        string     `json:"fullName"`
+	Username        *string    `json:"username,omitempty"`
+	PenName         *string    `json:"penName,omitempty"`
+	AvatarURL       string     `json:"avatarUrl"`
+	LoginWith       string     `json:"loginWith"`
+	IsEmailVerified bool       `json:"isEmailVerified"`
+	Phone           *string    `json:"phone,omitempty"`
+	IsPhoneVerified bool       `json:"isPhoneVerified"`
+	Instagram       *string    `json:"instagram,omitempty"`
+	Bio             *string    `json:"bio,omitempty"`
+	IsAuthor        bool       `json:"isAuthor"`
+	CreateDatetime  time.Time  `json:"createDatetime"`
+	UpdateDatetime  *time.Time `json:"updateDatetime,omitempty"`
+}
+
+// UpdateProfilePayload adalah body PATCH /v1/user/me. Field yang tidak dikirim tidak diubah.
+type UpdateProfilePayload struct {
+	FullName  *string `json:"fullName"`
+	Username  *string `json:"username"`
+	PenName   *string `json:"penName"`
+	AvatarURL *string `json:"avatarUrl"`
+	Instagram *string `json:"instagram"`
+	Phone     *string `json:"phone"`
+	Bio       *string `json:"bio"`
+}
+
+// ValidationErrorResponse adalah response error validasi dengan pesan per field.
+type ValidationErrorResponse struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields"`
+}
+
+// GetMyProfile mengembalikan profil pengguna yang sedang login.
+// @Summary      Profil Saya
+// @Description  Mengambil data profil milik pengguna yang sedang login.
+// @Tags         User
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200 {object} UserProfileResponse
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me [GET]
+func (c *UserController) GetMyProfile(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserUnauthorized,
+			Message: "Invalid access, user ID not found in token.",
+		})
+	}
+
+	return c.respondWithProfile(ctx, userId)
+}
+
+// UpdateMyProfile memperbarui sebagian data profil pengguna yang sedang login.
+// @Summary      Ubah Profil Saya
+// @Description  Memperbarui nama lengkap, username, nama pena, avatar, Instagram, atau nomor telepon. Hanya field yang dikirim yang diubah. Kirim string kosong pada phone untuk menghapusnya (tidak berlaku untuk penulis). Nama pena hanya bisa diubah oleh penulis.
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        profile body UpdateProfilePayload true "Field profil yang ingin diubah"
+// @Success      200 {object} UserProfileResponse
+// @Failure      400 {object} ValidationErrorResponse "Input tidak valid"
+// @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
+// @Failure      409 {object} ErrorResponse "Username, nama pena, atau nomor telepon sudah digunakan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/user/me [PATCH]
+func (c *UserController) UpdateMyProfile(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(int64)
+	if !ok || userId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserUnauthorized,
+			Message: "Invalid access, user ID not found in token.",
+		})
+	}
+
+	var payload UpdateProfilePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Code:    constants.ErrCodeBadRequest,
+			Message: "Cannot parse request body.",
+		})
+	}
+
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to find user by ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeInternalServer,
+			Message: "Failed to retrieve user data.",
+		})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserNotFound,
+			Message: "User associated with this token not found.",
+		})
+	}
+
+	// 1. Validasi per field
+	params, fieldErrors := validateProfileUpdate(payload, user)
+	if len(fieldErrors) > 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+			Code:    constants.ErrCodeBadRequest,
+			Message: "Some fields are invalid.",
+			Fields:  fieldErrors,
+		})
+	}
+
+	// 2. Cek keunikan sebelum update agar pesan error jelas
+	if params.Username != nil {
+		taken, err := c.userDAO.IsUsernameTakenByOther(ctx.Context(), *params.Username, userId)
+		if err != nil {
+			c.log.WithError(err).Error("Failed to check username availability")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "A server error occurred while checking username."})
+		}
+		if taken {
+			return c.respondProfileConflict(ctx, dao.ErrUsernameTaken)
+		}
+	}
+	if params.PenName != nil && *params.PenName != "" {
+		taken, err := c.userDAO.IsPenNameTakenByOther(ctx.Context(), *params.PenName, userId)
+		if err != nil {
+			c.log.WithError(err).Error("Failed to check pen name availability")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "A server error occurred while checking pen name."})
+		}
+		if taken {
+			return c.respondProfileConflict(ctx, dao.ErrPenNameTaken)
+		}
+	}
+	if params.Phone != nil && *params.Phone != "" {
+		taken, err := c.userDAO.IsPhoneTakenByOther(ctx.Context(), *params.Phone, userId)
+		if err != nil {
+			c.log.WithError(err).Error("Failed to check phone availability")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "A server error occurred while checking phone number."})
+		}
+		if taken {
+			return c.respondProfileConflict(ctx, dao.ErrPhoneTaken)
+		}
+	}
+
+	// 3. Simpan perubahan
+	if err := c.userDAO.UpdateProfile(ctx.Context(), userId, params); err != nil {
+		if errors.Is(err, dao.ErrUsernameTaken) || errors.Is(err, dao.ErrPenNameTaken) || errors.Is(err, dao.ErrPhoneTaken) {
+			return c.respondProfileConflict(ctx, err)
+		}
+		c.log.WithError(err).Errorf("Failed to update profile for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserUpdateFailed,
+			Message: "Failed to update user profile.",
+		})
+	}
+
+	return c.respondWithProfile(ctx, userId)
+}
+
+// respondWithProfile mengambil ulang data pengguna dan mengirimkannya sebagai UserProfileResponse.
+func (c *UserController) respondWithProfile(ctx *fiber.Ctx, userId int64) error {
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to find user by ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeInternalServer,
+			Message: "Failed to retrieve user data.",
+		})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserNotFound,
+			Message: "User associated with this token not found.",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(newUserProfileResponse(user))
+}
+
+// respondProfileConflict memetakan error keunikan dari DAO ke error code yang sesuai.
+func (c *UserController) respondProfileConflict(ctx *fiber.Ctx, err error) error {
+	resp := ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "This phone number is already used by another account."}
+	switch {
+	case errors.Is(err, dao.ErrUsernameTaken):
+		resp = ErrorResponse{Code: constants.ErrCodeAuthEmailOrUsernameTaken, Message: "This username is already taken, please choose another one."}
+	case errors.Is(err, dao.ErrPenNameTaken):
+		resp = ErrorResponse{Code: constants.ErrCodeUserPenNameTaken, Message: "This pen name is already taken, please choose another one."}
+	}
+	return ctx.Status(fiber.StatusConflict).JSON(resp)
+}
+
+func newUserProfileResponse(user *tables.User) UserProfileResponse {
+	return UserProfileResponse{
+		UserId:          user.UserId,
+		Email:           user.Email,
+		FullName:        user.FullName,
+		Username:        user.Username,
+		PenName:         user.PenName,
+		AvatarURL:       user.AvatarURL,
+		LoginWith:       user.LoginWith,
+		IsEmailVerified: user.IsEmailVerified,
+		Phone:           user.Phone,
+		IsPhoneVerified: user.IsPhoneVerified,
+		Instagram:       user.Instagram,
+		Bio:             user.Bio,
+		IsAuthor:        user.FlgAuthor == "Y",
+		CreateDatetime:  user.CreateDatetime,
+		UpdateDatetime:  user.UpdateDatetime,
+	}
+}
+
+// validateProfileUpdate merapikan dan memvalidasi setiap field yang dikirim.
+// Mengembalikan parameter update untuk DAO dan daftar error per field (key sesuai nama JSON).
+func validateProfileUpdate(payload UpdateProfilePayload, user *tables.User) (dao.ProfileUpdate, map[string]string) {
+	var params dao.ProfileUpdate
+	fieldErrors := map[string]string{}
+
+	if payload.FullName != nil {
+		fullName := strings.TrimSpace(*payload.FullName)
+		switch n := utf8.RuneCountInString(fullName); {
+		case n == 0:
+			fieldErrors["fullName"] = "Full name must not be empty."
+		case n > 100:
+			fieldErrors["fullName"] = "Full name must be at most 100 characters."
+		default:
+			params.FullName = &fullName
+		}
+	}
+
+	if payload.Username != nil {
+		username := strings.TrimSpace(*payload.Username)
+		switch n := utf8.RuneCountInString(username); {
+		case n < 3 || n > 50:
+			fieldErrors["username"] = "Username must be between 3 and 50 characters."
+		case strings.ContainsAny(username, "@ \t\n"):
+			fieldErrors["username"] = "Username must not contain '@' or whitespace."
+		default:
+			params.Username = &username
+		}
+	}
+
+	if payload.PenName != nil {
+		penName := strings.TrimSpace(*payload.PenName)
+		switch n := utf8.RuneCountInString(penName); {
+		case user.FlgAuthor != "Y":
+			fieldErrors["penName"] = "Pen name can only be set by authors, use the author request instead."
+		case n == 0:
+			fieldErrors["penName"] = "Pen name must not be empty for an author."
+		case n > 100:
+			fieldErrors["penName"] = "Pen name must be at most 100 characters."
+		default:
+			params.PenName = &penName
+		}
+	}
+
+	if payload.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*payload.AvatarURL)
+		if avatarURL != "" && !isHTTPURL(avatarURL) {
+			fieldErrors["avatarUrl"] = "Avatar URL must be a valid http or https URL."
+		} else {
+			params.AvatarURL = &avatarURL
+		}
+	}
+
+	if payload.Instagram != nil {
+		instagram := strings.TrimPrefix(strings.TrimSpace(*payload.Instagram), "@")
+		if instagram != "" && !instagramPattern.MatchString(instagram) {
+			fieldErrors["instagram"] = "Instagram username may only contain letters, numbers, dots and underscores (max 30)."
+		} else {
+			params.Instagram = &instagram
+		}
+	}
+
+	if payload.Phone != nil {
+		phone := phoneSeparators.Replace(strings.TrimSpace(*payload.Phone))
+		if phone != "" && !phonePattern.MatchString(phone) {
+			fieldErrors["phone"] = "Phone number must contain 8 to 15 digits, optionally starting with '+'."
+		} else if phone == "" && user.FlgAuthor == "Y" {
+			fieldErrors["phone"] = "Authors must keep a phone number on their profile."
+		} else {
+			params.Phone = &phone
+		}
+	}
+
+	if payload.Bio != nil {
+		bio := strings.TrimSpace(*payload.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			fieldErrors["bio"] = "Bio must be at most 500 characters."
+		} else {
+			params.Bio = &bio
+		}
+	}
+
+	return params, fieldErrors
+}
+
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
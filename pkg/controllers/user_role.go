@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"errors"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/tables"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// UserRoleController menangani pengelolaan role pengguna oleh admin.
+type UserRoleController struct {
+	roleDAO *dao.UserRoleDao
+	log     *logrus.Logger
+}
+
+func NewUserRoleController(roleDAO *dao.UserRoleDao) *UserRoleController {
+	return &UserRoleController{
+		roleDAO: roleDAO,
+		log:     logrus.New(),
+	}
+}
+
+// GrantRolePayload adalah body untuk memberikan role ke pengguna.
+type GrantRolePayload struct {
+	Role string `json:"role" example:"moderator"`
+}
+
+// GetUserRoles adalah handler untuk melihat role milik seorang pengguna.
+// @Summary      Lihat Role Pengguna (Admin)
+// @Description  Mengambil daftar role milik pengguna beserta admin yang memberikannya.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Success      200 {array} tables.UserRole
+// @Failure      400 {object} ErrorResponse "ID tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan admin"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/admin/users/{userId}/roles [GET]
+func (c *UserRoleController) GetUserRoles(ctx *fiber.Ctx) error {
+	targetUserId, err := strconv.ParseInt(ctx.Params("userId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid user ID."})
+	}
+
+	roles, err := c.roleDAO.ListUserRoles(ctx.Context(), targetUserId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mengambil role user ID %d", targetUserId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to retrieve user roles."})
+	}
+	if roles == nil {
+		roles = make([]tables.UserRole, 0)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(roles)
+}
+
+// GrantRole adalah handler untuk memberikan role ke pengguna.
+// @Summary      Berikan Role (Admin)
+// @Description  Memberikan role moderator atau admin ke pengguna. Role author hanya diberikan lewat persetujuan pengajuan penulis. Perubahan berlaku setelah access token pengguna diperbarui.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Param        role body GrantRolePayload true "Role yang diberikan"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "Role tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan admin"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak ditemukan"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/admin/users/{userId}/roles [POST]
+func (c *UserRoleController) GrantRole(ctx *fiber.Ctx) error {
+	adminId, ok := ctx.Locals("userId").(int64)
+	if !ok || adminId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	targetUserId, err := strconv.ParseInt(ctx.Params("userId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid user ID."})
+	}
+
+	var payload GrantRolePayload
+	if err := ctx.BodyParser(&payload); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Cannot parse request body."})
+	}
+	role := strings.ToLower(strings.TrimSpace(payload.Role))
+	if role != tables.RoleModerator && role != tables.RoleAdmin {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeUserInvalidRole, Message: "Only the moderator and admin roles can be granted manually."})
+	}
+
+	if err := c.roleDAO.GrantRole(ctx.Context(), targetUserId, role, &adminId); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User not found."})
+		}
+		c.log.WithError(err).Errorf("Gagal memberikan role %s ke user ID %d", role, targetUserId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to grant role."})
+	}
+
+	c.log.Infof("Admin %d memberikan role %s ke user ID %d", adminId, role, targetUserId)
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "role_granted", "message": "Role granted successfully."})
+}
+
+// RevokeRole adalah handler untuk mencabut role dari pengguna.
+// @Summary      Cabut Role (Admin)
+// @Description  Mencabut role author, moderator, atau admin dari pengguna. Admin tidak bisa mencabut role admin miliknya sendiri.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId path int true "ID Pengguna"
+// @Param        role path string true "Role yang dicabut"
+// @Success      200 {object} object{code=string,message=string}
+// @Failure      400 {object} ErrorResponse "Role tidak valid"
+// @Failure      403 {object} ErrorResponse "Bukan admin"
+// @Failure      404 {object} ErrorResponse "Pengguna tidak memiliki role tersebut"
+// @Failure      500 {object} ErrorResponse "Error internal server"
+// @Router       /v1/admin/users/{userId}/roles/{role} [DELETE]
+func (c *UserRoleController) RevokeRole(ctx *fiber.Ctx) error {
+	adminId, ok := ctx.Locals("userId").(int64)
+	if !ok || adminId == 0 {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Code: constants.ErrCodeUserUnauthorized, Message: "Invalid access."})
+	}
+
+	targetUserId, err := strconv.ParseInt(ctx.Params("userId"), 10, 64)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeBadRequest, Message: "Invalid user ID."})
+	}
+
+	role := strings.ToLower(ctx.Params("role"))
+	if !tables.IsValidRole(role) || role == tables.RoleReader {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeUserInvalidRole, Message: "Only the author, moderator and admin roles can be revoked."})
+	}
+	// Mencegah admin mengunci dirinya sendiri dari back-office
+	if role == tables.RoleAdmin && targetUserId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Code: constants.ErrCodeUserInvalidRole, Message: "You cannot revoke your own admin role."})
+	}
+
+	revoked, err := c.roleDAO.RevokeRole(ctx.Context(), targetUserId, role)
+	if err != nil {
+		c.log.WithError(err).Errorf("Gagal mencabut role %s dari user ID %d", role, targetUserId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Code: constants.ErrCodeInternalServer, Message: "Failed to revoke role."})
+	}
+	if !revoked {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{Code: constants.ErrCodeUserNotFound, Message: "User does not have this role."})
+	}
+
+	c.log.Infof("Admin %d mencabut role %s dari user ID %d", adminId, role, targetUserId)
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{"code": "role_revoked", "message": "Role revoked successfully."})
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"noversystem/pkg/config"
 	"noversystem/pkg/constants"
 	"noversystem/pkg/dao"
 	"noversystem/pkg/tables"
@@ -12,15 +14,19 @@ import (
 
 // UserController menangani logika yang berhubungan dengan pengguna.
 type UserController struct {
-	userDAO *dao.UserDao
-	log     *logrus.Logger
+	userDAO              *dao.UserDao
+	authorApplicationDAO *dao.AuthorApplicationDao
+	bankDAO              *dao.BankDao
+	log                  *logrus.Logger
 }
 
 // NewUserController membuat instance baru dari UserController.
-func NewUserController(userDAO *dao.UserDao) *UserController {
+func NewUserController(userDAO *dao.UserDao, authorApplicationDAO *dao.AuthorApplicationDao, bankDAO *dao.BankDao) *UserController {
 	return &UserController{
-		userDAO: userDAO,
-		log:     logrus.New(), // Inisialisasi logger
+		userDAO:              userDAO,
+		authorApplicationDAO: authorApplicationDAO,
+		bankDAO:              bankDAO,
+		log:                  logrus.New(), // Inisialisasi logger
 	}
 }
 
@@ -33,18 +39,19 @@ type AuthorRequestPayload struct {
 	AccountNumber string `json:"accountNumber"`
 }
 
-// RequestBecomeAuthor adalah handler untuk permintaan menjadi penulis.
-// @Summary      Permintaan menjadi Penulis
-// @Description  Memperbarui profil pengguna untuk menjadi penulis dengan melengkapi data yang diperlukan. Endpoint ini memerlukan otentikasi.
+// RequestBecomeAuthor adalah handler untuk mengajukan permintaan menjadi penulis.
+// @Summary      Pengajuan menjadi Penulis
+// @Description  Mengirim pengajuan menjadi penulis dengan data yang diperlukan. Pengguna baru menjadi penulis setelah pengajuan disetujui admin. Endpoint ini memerlukan otentikasi.
 // @Tags         User
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        author_request body AuthorRequestPayload true "Data untuk menjadi penulis"
-// @Success      200 {object} object{code=string,message=string} "Pesan sukses"
+// @Success      202 {object} tables.AuthorApplication "Pengajuan tersimpan dan menunggu review"
 // @Failure      400 {object} ErrorResponse "Input tidak valid"
 // @Failure      401 {object} ErrorResponse "Tidak terotentikasi"
-// @Failure      409 {object} ErrorResponse "Nama pena sudah digunakan"
+// @Failure      403 {object} ErrorResponse "Nomor telepon belum terverifikasi (jika REQUIRE_VERIFIED_PHONE aktif)"
+// @Failure      409 {object} ErrorResponse "Sudah menjadi penulis, masih ada pengajuan yang menunggu review, atau nama pena/nomor telepon sudah digunakan"
 // @Failure      500 {object} ErrorResponse "Error internal server"
 // @Router       /v1/user/request-author [POST]
 func (c *UserController) RequestBecomeAuthor(ctx *fiber.Ctx) error {
@@ -67,8 +74,8 @@ func (c *UserController) RequestBecomeAuthor(ctx *fiber.Ctx) error {
 	}
 
 	payload.PenName = strings.TrimSpace(payload.PenName)
-	payload.Phone = strings.TrimSpace(payload.Phone)
-	payload.AccountNumber = strings.TrimSpace(payload.AccountNumber)
+	payload.Phone = phoneSeparators.Replace(strings.TrimSpace(payload.Phone))
+	payload.Instagram = strings.TrimPrefix(strings.TrimSpace(payload.Instagram), "@")
 
 	// 3. Validasi input yang wajib diisi
 	if payload.PenName == "" || payload.Phone == "" || payload.BankId == 0 || payload.AccountNumber == "" {
@@ -77,9 +84,52 @@ func (c *UserController) RequestBecomeAuthor(ctx *fiber.Ctx) error {
 			Message: "Pen name, phone, bank, and account number fields are required.",
 		})
 	}
+	if !phonePattern.MatchString(payload.Phone) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Code:    constants.ErrCodeBadRequest,
+			Message: "Phone number must contain 8 to 15 digits, optionally starting with '+'.",
+		})
+	}
+
+	_, accountNumber, status, errResp := validatePayoutAccount(ctx.Context(), c.log, c.bankDAO, payload.BankId, payload.AccountNumber)
+	if errResp != nil {
+		return ctx.Status(status).JSON(errResp)
+	}
+	payload.AccountNumber = accountNumber
 
-	// 4. Cek apakah nama pena sudah digunakan
+	// 4. Pastikan pengguna belum menjadi penulis
+	user, err := c.userDAO.FindUserByID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to find user by ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeInternalServer,
+			Message: "Failed to retrieve user data.",
+		})
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Code:    constants.ErrCodeUserNotFound,
+			Message: "User associated with this token not found.",
+		})
+	}
+	if user.FlgAuthor == "Y" {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Code:    constants.ErrCodeAuthorAlreadyAuthor,
+			Message: "You are already an author.",
+		})
+	}
+	if config.Cfg.Phone.RequireVerified && (!user.IsPhoneVerified || user.Phone == nil || *user.Phone != payload.Phone) {
+		return ctx.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Code:    constants.ErrCodePhoneNotVerified,
+			Message: "Please verify this phone number via /v1/user/me/phone/otp before applying.",
+		})
+	}
+
+	// 5. Cek apakah nama pena sudah digunakan penulis lain atau sedang diajukan pengguna lain
 	isTaken, err := c.userDAO.IsPenNameTaken(ctx.Context(), payload.PenName)
+	if err == nil && !isTaken {
+		isTaken, err = c.authorApplicationDAO.IsPenNameRequested(ctx.Context(), payload.PenName, userId)
+	}
 	if err != nil {
 		c.log.WithError(err).Error("Failed to check pen name availability")
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
@@ -94,46 +144,60 @@ func (c *UserController) RequestBecomeAuthor(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// 5. Siapkan data untuk update dan panggil DAO
-	updateParams := dao.AuthorUpdateRequest{
+	isTaken, err = c.userDAO.IsPhoneTakenByOther(ctx.Context(), payload.Phone, userId)
+	if err != nil {
+		c.log.WithError(err).Error("Failed to check phone availability")
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeInternalServer,
+			Message: "A server error occurred while checking phone number.",
+		})
+	}
+	if isTaken {
+		return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Code:    constants.ErrCodeAuthEmailOrUsernameTaken,
+			Message: "This phone number is already used by another account.",
+		})
+	}
+
+	// 6. Simpan pengajuan, status penulis baru diberikan setelah disetujui admin
+	application := &tables.AuthorApplication{
+		UserID:        userId,
 		PenName:       payload.PenName,
 		Phone:         payload.Phone,
 		Instagram:     payload.Instagram,
-		BankId:        payload.BankId,
+		BankID:        payload.BankId,
 		AccountNumber: payload.AccountNumber,
 	}
 
-	if err := c.userDAO.UpdateUserToAuthor(ctx.Context(), userId, updateParams); err != nil {
-		c.log.WithError(err).Error("Failed to update user to author")
-		// Cek apakah error karena pengguna tidak ditemukan
-		if err.Error() == "pengguna tidak ditemukan atau tidak ada data yang diperbarui" {
-			return ctx.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-				Code:    constants.ErrCodeUserNotFound,
-				Message: err.Error(),
+	if err := c.authorApplicationDAO.CreateApplication(ctx.Context(), application); err != nil {
+		if errors.Is(err, dao.ErrApplicationPending) {
+			return ctx.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Code:    constants.ErrCodeAuthorApplicationPending,
+				Message: "You already have an author application waiting for review.",
 			})
 		}
-		// Error server lainnya
+		c.log.WithError(err).Error("Failed to create author application")
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Code:    constants.ErrCodeUserUpdateFailed,
-			Message: "Failed to update user profile.",
+			Code:    constants.ErrCodeInternalServer,
+			Message: "Failed to submit author application.",
 		})
 	}
 
-	// 6. Kirim response sukses
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"code":    "author_request_success",
-		"message": "Request to become an author was successful, your profile has been updated.",
-	})
+	// 7. Kirim response, pengajuan menunggu review
+	return ctx.Status(fiber.StatusAccepted).JSON(application)
 }
 
 type AuthorStatusResponse struct {
-	IsAuthor bool         `json:"isAuthor"`
-	User     *tables.User `json:"user"`
+	IsAuthor bool `json:"isAuthor"`
+	// Status pengajuan terbaru: NONE, PENDING, APPROVED, atau REJECTED
+	ApplicationStatus string                    `json:"applicationStatus"`
+	Application       *tables.AuthorApplication `json:"application,omitempty"`
+	User              *tables.User              `json:"user"`
 }
 
 // CheckAuthorStatus memeriksa status penulis dan mengembalikan data profil lengkap.
 // @Summary      Cek Status Penulis & Profil
-// @Description  Memvalidasi token, memeriksa apakah pengguna adalah penulis, dan mengembalikan data profil lengkap pengguna.
+// @Description  Memvalidasi token, memeriksa apakah pengguna adalah penulis beserta status pengajuan terakhirnya, dan mengembalikan data profil lengkap pengguna.
 // @Tags         User
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -169,13 +233,29 @@ func (c *UserController) CheckAuthorStatus(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// 3. Hapus hash password sebelum mengirim response
+	// 3. Ambil pengajuan penulis terakhir untuk menampilkan status review
+	application, err := c.authorApplicationDAO.FindLatestByUserID(ctx.Context(), userId)
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed to find author application for user ID %d", userId)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Code:    constants.ErrCodeInternalServer,
+			Message: "Failed to retrieve author application.",
+		})
+	}
+	applicationStatus := "NONE"
+	if application != nil {
+		applicationStatus = application.Status
+	}
+
+	// 4. Hapus hash password sebelum mengirim response
 	user.Password = ""
 
-	// 4. Siapkan dan kembalikan response lengkap
+	// 5. Siapkan dan kembalikan response lengkap
 	response := AuthorStatusResponse{
-		IsAuthor: user.FlgAuthor == "Y",
-		User:     user,
+		IsAuthor:          user.FlgAuthor == "Y",
+		ApplicationStatus: applicationStatus,
+		Application:       application,
+		User:              user,
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(response)
@@ -0,0 +1,51 @@
+// Package cursor menyediakan token cursor opaque untuk pagination keyset (seek). Token berisi
+// nilai kolom urutan dari baris terakhir (atau pertama) yang sudah dilihat klien, sehingga halaman
+// berikutnya tidak bergeser saat ada baris baru yang masuk.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalid dikembalikan jika token tidak bisa dibaca atau dibuat untuk daftar/urutan lain.
+var ErrInvalid = errors.New("cursor tidak valid")
+
+// Cursor menandai posisi dalam daftar yang diurutkan secara keyset.
+type Cursor struct {
+	Key      string   `json:"k"`           // Nama daftar/urutan, agar cursor tidak dipakai di urutan lain
+	Values   []string `json:"v"`           // Nilai kolom urutan, termasuk ID sebagai pemutus seri
+	Backward bool     `json:"b,omitempty"` // true untuk mengambil halaman sebelum posisi ini
+}
+
+// Page berisi token cursor ke halaman berikutnya dan sebelumnya. Token kosong berarti tidak ada halaman lagi.
+type Page struct {
+	Next string
+	Prev string
+}
+
+// Encode mengubah cursor menjadi token yang aman dipakai di query string.
+func (c Cursor) Encode() string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// Decode membaca token cursor untuk daftar dengan nama key. Token kosong menghasilkan nil tanpa error.
+func Decode(token, key string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalid
+	}
+	if c.Key != key || len(c.Values) == 0 {
+		return nil, ErrInvalid
+	}
+	return &c, nil
+}
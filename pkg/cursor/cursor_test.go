@@ -0,0 +1,67 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	tests := []Cursor{
+		{Key: "books:newest", Values: []string{"2026-10-17T10:00:00.123456Z", "42"}},
+		{Key: "notifications", Values: []string{"2026-10-17T10:00:00Z", "7"}, Backward: true},
+		{Key: "books:top_rated", Values: []string{"4.5", "2026-10-17T10:00:00Z", "9"}},
+	}
+	for _, want := range tests {
+		token := want.Encode()
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("token %q tidak aman dipakai di query string", token)
+		}
+		got, err := Decode(token, want.Key)
+		if err != nil {
+			t.Fatalf("Decode(%q) error: %v", token, err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("Decode(Encode(%+v)) = %+v", want, *got)
+		}
+	}
+}
+
+func TestDecodeEmptyToken(t *testing.T) {
+	got, err := Decode("", "books:newest")
+	if got != nil || err != nil {
+		t.Fatalf("Decode(\"\") = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestDecodeRejectsInvalidTokens(t *testing.T) {
+	valid := Cursor{Key: "books:newest", Values: []string{"2026-10-17T10:00:00Z", "42"}}.Encode()
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		key   string
+	}{
+		{"not base64", "not a cursor!", "books:newest"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"k":"books:newest","v":["1"]}`)) + "=", "books:newest"},
+		{"not json", encode("hello"), "books:newest"},
+		{"tampered payload", valid[:len(valid)-2] + "xx", "books:newest"},
+		{"cursor for another list", valid, "books:most_viewed"},
+		{"no values", encode(`{"k":"books:newest","v":[]}`), "books:newest"},
+		{"missing values", encode(`{"k":"books:newest"}`), "books:newest"},
+		{"wrong value type", encode(`{"k":"books:newest","v":[1,2]}`), "books:newest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.token, tt.key)
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode() = %+v, %v; want ErrInvalid", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletedUserName adalah nama yang ditampilkan pada komentar dan ulasan milik akun yang sudah dihapus.
+const DeletedUserName = "Pengguna Terhapus"
+
+// AccountDao menangani ekspor data pribadi dan penghapusan akun.
+type AccountDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewAccountDao(db *pgxpool.Pool) *AccountDao {
+	return &AccountDao{DB: db}
+}
+
+// ExportUserData mengumpulkan seluruh data pribadi pengguna. Semua query dijalankan dalam satu
+// transaksi read-only REPEATABLE READ agar isi arsip konsisten satu sama lain.
+// Mengembalikan nil jika pengguna tidak ditemukan atau sudah dihapus.
+func (d *AccountDao) ExportUserData(ctx context.Context, userID int64) (*tables.UserDataExport, error) {
+	tx, err := d.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	export := &tables.UserDataExport{GeneratedAt: time.Now().UTC()}
+
+	const profileQuery = `
+		SELECT
+			user_id, email, full_name, username, pen_name, avatar_url, bio, login_with,
+			is_email_verified, phone, is_phone_verified, instagram, bank_id, account_number, create_datetime, update_datetime
+		FROM users
+		WHERE user_id = $1 AND deleted_datetime IS NULL`
+	if err := pgxscan.Get(ctx, tx, &export.Profile, profileQuery, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil profil: %w", err)
+	}
+
+	const commentsQuery = `
+		SELECT comment_id, 'BOOK' AS target_type, book_id AS target_id, parent_comment_id, comment_text, create_datetime, update_datetime
+		FROM book_comments WHERE user_id = $1
+		UNION ALL
+		SELECT comment_id, 'CHAPTER', chapter_id, parent_comment_id, comment_text, create_datetime, update_datetime
+		FROM chapter_comments WHERE user_id = $1
+		UNION ALL
+		SELECT comment_id, 'REVIEW', review_id, parent_comment_id, comment_text, create_datetime, update_datetime
+		FROM review_comments WHERE user_id = $1
+		ORDER BY create_datetime`
+	if err := pgxscan.Select(ctx, tx, &export.Comments, commentsQuery, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil komentar: %w", err)
+	}
+
+	const reviewsQuery = `
+		SELECT r.review_id, r.book_id, b.title AS book_title, r.rating, r.review_text, r.create_datetime, r.update_datetime
+		FROM reviews r
+		LEFT JOIN books b ON b.book_id = r.book_id
+		WHERE r.user_id = $1
+		ORDER BY r.create_datetime`
+	if err := pgxscan.Select(ctx, tx, &export.Reviews, reviewsQuery, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil ulasan: %w", err)
+	}
+
+	const checkinsQuery = `
+		SELECT TO_CHAR(checkin_date, 'YYYY-MM-DD') AS checkin_date, consecutive_streak
+		FROM user_daily_checkins
+		WHERE user_id = $1
+		ORDER BY checkin_date`
+	if err := pgxscan.Select(ctx, tx, &export.Checkins, checkinsQuery, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil riwayat check-in: %w", err)
+	}
+
+	const missionQuery = `
+		SELECT TO_CHAR(p.progress_date, 'YYYY-MM-DD') AS progress_date, p.mission_id, m.title AS mission_title,
+			p.current_value, p.last_claimed_tier_id
+		FROM user_mission_progress p
+		JOIN missions m ON m.mission_id = p.mission_id
+		WHERE p.user_id = $1
+		ORDER BY p.progress_date, p.mission_id`
+	if err := pgxscan.Select(ctx, tx, &export.MissionProgress, missionQuery, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil progres misi: %w", err)
+	}
+
+	const transactionsQuery = `
+		SELECT transaction_id, user_id, transaction_type, coin_type, amount, description, expiry_date, create_datetime
+		FROM coin_transactions
+		WHERE user_id = $1
+		ORDER BY create_datetime, transaction_id`
+	if err := pgxscan.Select(ctx, tx, &export.CoinTransactions, transactionsQuery, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil transaksi koin: %w", err)
+	}
+
+	return export, nil
+}
+
+// DeleteAccount menganonimkan akun pengguna dalam satu transaksi:
+//   - data profil dan kredensial dikosongkan sehingga akun tidak bisa dipakai login lagi,
+//   - komentar dan ulasan tetap ada tetapi tampil atas nama DeletedUserName,
+//   - wallet dan coin_transactions dipertahankan untuk keperluan akuntansi,
+//   - data pribadi lain (pengajuan penulis, genre favorit, follow, notifikasi, token reset) dihapus,
+//   - semua sesi dicabut.
+//
+// Mengembalikan ErrUserNotFound jika pengguna tidak ada atau sudah dihapus.
+func (d *AccountDao) DeleteAccount(ctx context.Context, userID int64) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Email dan user_code tetap unik per user_id supaya tidak bentrok dengan constraint UNIQUE,
+	// dan email/Google ID asli bisa dipakai mendaftar ulang.
+	const anonymizeQuery = `
+		UPDATE users SET
+			user_code = 'deleted:' || user_id,
+			email = 'deleted-' || user_id || '@deleted.invalid',
+			password = '',
+			full_name = $2,
+			username = NULL,
+			pen_name = NULL,
+			avatar_url = '',
+			bio = NULL,
+			phone = NULL,
+			is_phone_verified = FALSE,
+			instagram = NULL,
+			bank_id = NULL,
+			account_number = NULL,
+			login_with = 'deleted',
+			is_email_verified = FALSE,
+			flg_author = 'N',
+			deleted_datetime = NOW()
+		WHERE user_id = $1 AND deleted_datetime IS NULL`
+	cmdTag, err := tx.Exec(ctx, anonymizeQuery, userID, DeletedUserName)
+	if err != nil {
+		return fmt.Errorf("gagal menganonimkan pengguna: %w", err)
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return ErrUserNotFound
+	}
+
+	cleanupQueries := []string{
+		`UPDATE user_sessions SET revoked_datetime = NOW() WHERE user_id = $1 AND revoked_datetime IS NULL`,
+		`DELETE FROM password_reset_tokens WHERE user_id = $1`,
+		`DELETE FROM user_totp WHERE user_id = $1`,
+		`DELETE FROM user_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM phone_verifications WHERE user_id = $1`,
+		// Riwayat rekening pencairan disimpan untuk catatan keuangan, hanya jejak perangkatnya yang dihapus
+		`UPDATE payout_account_changes SET ip_address = '', user_agent = '' WHERE user_id = $1`,
+		`DELETE FROM author_applications WHERE user_id = $1`,
+		`DELETE FROM user_roles WHERE user_id = $1`,
+		`DELETE FROM user_genres WHERE user_id = $1`,
+		`DELETE FROM author_follows WHERE follower_id = $1 OR author_id = $1`,
+		`DELETE FROM user_blocks WHERE blocker_id = $1 OR blocked_id = $1`,
+		`DELETE FROM system_notifications WHERE user_id = $1`,
+		`UPDATE login_attempts SET user_id = NULL, identifier = '' WHERE user_id = $1`,
+	}
+	for _, query := range cleanupQueries {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("gagal menghapus data pribadi pengguna: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
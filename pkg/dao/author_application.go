@@ -0,0 +1,247 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrApplicationPending dikembalikan jika pengguna masih memiliki pengajuan yang menunggu review.
+	ErrApplicationPending = errors.New("masih ada pengajuan penulis yang menunggu review")
+	// ErrApplicationNotFound dikembalikan jika pengajuan tidak ditemukan.
+	ErrApplicationNotFound = errors.New("pengajuan penulis tidak ditemukan")
+	// ErrApplicationNotPending dikembalikan jika pengajuan sudah diputuskan sebelumnya.
+	ErrApplicationNotPending = errors.New("pengajuan penulis sudah diputuskan")
+)
+
+// AuthorApplicationDao menangani operasi database untuk tabel author_applications.
+type AuthorApplicationDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewAuthorApplicationDao(db *pgxpool.Pool) *AuthorApplicationDao {
+	return &AuthorApplicationDao{DB: db}
+}
+
+const authorApplicationColumns = `
+	aa.application_id, aa.user_id, aa.pen_name, aa.phone, aa.instagram, aa.bank_id, aa.account_number,
+	aa.status, aa.reason, aa.reviewer_id, aa.reviewed_datetime, aa.create_datetime, aa.update_datetime`
+
+// CreateApplication menyimpan pengajuan penulis baru dengan status PENDING.
+func (d *AuthorApplicationDao) CreateApplication(ctx context.Context, app *tables.AuthorApplication) error {
+	const query = `
+		INSERT INTO author_applications (user_id, pen_name, phone, instagram, bank_id, account_number)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING application_id, status, create_datetime`
+
+	err := d.DB.QueryRow(ctx, query,
+		app.UserID, app.PenName, app.Phone, app.Instagram, app.BankID, app.AccountNumber,
+	).Scan(&app.ApplicationID, &app.Status, &app.CreateDatetime)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrApplicationPending
+		}
+		return fmt.Errorf("gagal menyimpan pengajuan penulis: %w", err)
+	}
+	return nil
+}
+
+// FindLatestByUserID mengambil pengajuan terbaru milik pengguna, atau nil jika belum pernah mengajukan.
+func (d *AuthorApplicationDao) FindLatestByUserID(ctx context.Context, userID int64) (*tables.AuthorApplication, error) {
+	var app tables.AuthorApplication
+	query := `SELECT ` + authorApplicationColumns + `
+		FROM author_applications aa
+		WHERE aa.user_id = $1
+		ORDER BY aa.create_datetime DESC
+		LIMIT 1`
+
+	err := pgxscan.Get(ctx, d.DB, &app, query, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil pengajuan penulis: %w", err)
+	}
+	return &app, nil
+}
+
+// IsPenNameRequested memeriksa apakah nama pena sedang diajukan oleh pengguna lain dan belum diputuskan.
+func (d *AuthorApplicationDao) IsPenNameRequested(ctx context.Context, penName string, userID int64) (bool, error) {
+	var requested bool
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM author_applications
+			WHERE pen_name = $1 AND user_id <> $2 AND status = 'PENDING'
+		)`
+	err := d.DB.QueryRow(ctx, query, penName, userID).Scan(&requested)
+	return requested, err
+}
+
+// ListApplications mengambil daftar pengajuan beserta data pemohon, difilter berdasarkan status jika diisi.
+func (d *AuthorApplicationDao) ListApplications(ctx context.Context, status string, limit, offset int) ([]tables.AuthorApplication, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	builder := psql.Select(authorApplicationColumns, "u.full_name AS applicant_name", "u.email AS applicant_email").
+		From("author_applications aa").
+		Join("users u ON u.user_id = aa.user_id").
+		OrderBy("aa.create_datetime ASC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+	if status != "" {
+		builder = builder.Where(squirrel.Eq{"aa.status": status})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []tables.AuthorApplication
+	if err := pgxscan.Select(ctx, d.DB, &apps, query, args...); err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar pengajuan penulis: %w", err)
+	}
+	return apps, nil
+}
+
+// CountApplications menghitung jumlah pengajuan, difilter berdasarkan status jika diisi.
+func (d *AuthorApplicationDao) CountApplications(ctx context.Context, status string) (int64, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	builder := psql.Select("COUNT(*)").From("author_applications")
+	if status != "" {
+		builder = builder.Where(squirrel.Eq{"status": status})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = d.DB.QueryRow(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// DecideApplication menyetujui atau menolak pengajuan dalam satu transaksi.
+// Jika disetujui, data penulis disalin ke tabel users, flg_author diubah menjadi 'Y', dan role author diberikan.
+// Pemohon selalu menerima notifikasi hasil keputusan.
+func (d *AuthorApplicationDao) DecideApplication(ctx context.Context, applicationID, reviewerID int64, approve bool, reason string) (*tables.AuthorApplication, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Langkah 1: Kunci pengajuan agar tidak diputuskan dua admin sekaligus
+	var app tables.AuthorApplication
+	query := `SELECT ` + authorApplicationColumns + `
+		FROM author_applications aa
+		WHERE aa.application_id = $1
+		FOR UPDATE`
+	if err := pgxscan.Get(ctx, tx, &app, query, applicationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, fmt.Errorf("gagal mengambil pengajuan penulis: %w", err)
+	}
+	if app.Status != tables.AuthorApplicationPending {
+		return nil, ErrApplicationNotPending
+	}
+
+	status := tables.AuthorApplicationRejected
+	notifType := "AUTHOR_APPLICATION_REJECTED"
+	content := fmt.Sprintf("Pengajuan Anda menjadi penulis dengan nama pena '%s' ditolak. Alasan: %s", app.PenName, reason)
+	if approve {
+		status = tables.AuthorApplicationApproved
+		notifType = "AUTHOR_APPLICATION_APPROVED"
+		content = fmt.Sprintf("Selamat! Pengajuan Anda menjadi penulis dengan nama pena '%s' telah disetujui.", app.PenName)
+	}
+
+	// Langkah 2: Simpan keputusan
+	err = tx.QueryRow(ctx, `
+		UPDATE author_applications SET
+			status = $1,
+			reason = $2,
+			reviewer_id = $3,
+			reviewed_datetime = NOW()
+		WHERE application_id = $4
+		RETURNING reviewer_id, reviewed_datetime`,
+		status, reason, reviewerID, applicationID,
+	).Scan(&app.ReviewerID, &app.ReviewedDatetime)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan keputusan pengajuan: %w", err)
+	}
+	app.Status = status
+	app.Reason = reason
+
+	// Langkah 3: Jadikan pengguna sebagai penulis
+	if approve {
+		// Rekening dari pengajuan sudah diperiksa admin, jadi langsung berlaku tanpa masa tunggu
+		_, err = tx.Exec(ctx, `
+			INSERT INTO payout_account_changes (user_id, old_bank_id, old_account_number, new_bank_id, new_account_number, source, effective_datetime)
+			SELECT u.user_id, b.bank_id, NULLIF(u.account_number, ''), nb.bank_id, $3, $4, NOW()
+			FROM users u
+			JOIN banks nb ON nb.bank_id = $2
+			LEFT JOIN banks b ON b.bank_id = u.bank_id
+			WHERE u.user_id = $1
+			  AND (u.bank_id IS DISTINCT FROM nb.bank_id OR u.account_number IS DISTINCT FROM $3)`,
+			app.UserID, app.BankID, app.AccountNumber, tables.PayoutAccountSourceAuthorApplication,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencatat riwayat rekening pencairan: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE users SET
+				pen_name = $1,
+				phone = $2,
+				is_phone_verified = (is_phone_verified AND phone IS NOT DISTINCT FROM $2),
+				instagram = $3,
+				bank_id = $4,
+				account_number = $5,
+				flg_author = 'Y',
+				update_datetime = NOW()
+			WHERE user_id = $6`,
+			app.PenName, app.Phone, app.Instagram, app.BankID, app.AccountNumber, app.UserID,
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				switch pgErr.ConstraintName {
+				case "users_pen_name_key":
+					return nil, ErrPenNameTaken
+				case "users_phone_key":
+					return nil, ErrPhoneTaken
+				}
+			}
+			return nil, fmt.Errorf("gagal menjadikan pengguna sebagai penulis: %w", err)
+		}
+		if err := grantRoleTx(ctx, tx, app.UserID, tables.RoleAuthor, &reviewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Langkah 4: Kirim notifikasi ke pemohon
+	_, err = tx.Exec(ctx, `
+		INSERT INTO system_notifications (user_id, actor_id, notification_type, content, related_entity_type, related_entity_id)
+		VALUES ($1, $2, $3, $4, 'AUTHOR_APPLICATION', $5)`,
+		app.UserID, reviewerID, notifType, content, app.ApplicationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat notifikasi: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return &app, nil
+}
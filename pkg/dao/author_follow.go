@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAuthorNotFound dikembalikan jika pengguna yang ingin diikuti tidak ada atau bukan penulis.
+var ErrAuthorNotFound = errors.New("penulis tidak ditemukan")
+
+// AuthorFollowDao menangani operasi database untuk tabel author_follows.
+type AuthorFollowDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewAuthorFollowDao(db *pgxpool.Pool) *AuthorFollowDao {
+	return &AuthorFollowDao{DB: db}
+}
+
+// Follow membuat pengguna mengikuti penulis. Tidak melakukan apa-apa jika sudah mengikuti.
+func (d *AuthorFollowDao) Follow(ctx context.Context, followerID, authorID int64) error {
+	var isAuthor bool
+	const checkQuery = `SELECT EXISTS (SELECT 1 FROM user_roles WHERE user_id = $1 AND role = 'author')`
+	if err := d.DB.QueryRow(ctx, checkQuery, authorID).Scan(&isAuthor); err != nil {
+		return fmt.Errorf("gagal memeriksa penulis: %w", err)
+	}
+	if !isAuthor {
+		return ErrAuthorNotFound
+	}
+
+	const query = `
+		INSERT INTO author_follows (follower_id, author_id)
+		VALUES ($1, $2)
+		ON CONFLICT (follower_id, author_id) DO NOTHING`
+	if _, err := d.DB.Exec(ctx, query, followerID, authorID); err != nil {
+		return fmt.Errorf("gagal mengikuti penulis: %w", err)
+	}
+	return nil
+}
+
+// Unfollow berhenti mengikuti penulis. Mengembalikan false jika memang belum mengikuti.
+func (d *AuthorFollowDao) Unfollow(ctx context.Context, followerID, authorID int64) (bool, error) {
+	cmdTag, err := d.DB.Exec(ctx, `DELETE FROM author_follows WHERE follower_id = $1 AND author_id = $2`, followerID, authorID)
+	if err != nil {
+		return false, fmt.Errorf("gagal berhenti mengikuti penulis: %w", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// IsFollowing memeriksa apakah pengguna sedang mengikuti penulis.
+func (d *AuthorFollowDao) IsFollowing(ctx context.Context, followerID, authorID int64) (bool, error) {
+	var following bool
+	const query = `SELECT EXISTS (SELECT 1 FROM author_follows WHERE follower_id = $1 AND author_id = $2)`
+	err := d.DB.QueryRow(ctx, query, followerID, authorID).Scan(&following)
+	return following, err
+}
+
+// CountFollowers menghitung jumlah pengikut seorang penulis.
+func (d *AuthorFollowDao) CountFollowers(ctx context.Context, authorID int64) (int64, error) {
+	var count int64
+	err := d.DB.QueryRow(ctx, `SELECT COUNT(*) FROM author_follows WHERE author_id = $1`, authorID).Scan(&count)
+	return count, err
+}
+
+// CountFollowing menghitung jumlah penulis yang diikuti seorang pengguna.
+func (d *AuthorFollowDao) CountFollowing(ctx context.Context, followerID int64) (int64, error) {
+	var count int64
+	err := d.DB.QueryRow(ctx, `SELECT COUNT(*) FROM author_follows WHERE follower_id = $1`, followerID).Scan(&count)
+	return count, err
+}
+
+// ListFollowers mengambil pengikut seorang penulis, yang terbaru lebih dulu.
+func (d *AuthorFollowDao) ListFollowers(ctx context.Context, authorID int64, limit, offset int) ([]tables.FollowUser, error) {
+	var followers []tables.FollowUser
+	const query = `
+		SELECT
+			u.user_id,
+			COALESCE(u.pen_name, u.username, '') AS display_name,
+			u.avatar_url,
+			af.create_datetime AS follow_datetime
+		FROM author_follows af
+		JOIN users u ON u.user_id = af.follower_id
+		WHERE af.author_id = $1
+		ORDER BY af.create_datetime DESC
+		LIMIT $2 OFFSET $3`
+
+	if err := pgxscan.Select(ctx, d.DB, &followers, query, authorID, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar pengikut: %w", err)
+	}
+	return followers, nil
+}
+
+// ListFollowing mengambil penulis yang diikuti seorang pengguna, yang terbaru lebih dulu.
+func (d *AuthorFollowDao) ListFollowing(ctx context.Context, followerID int64, limit, offset int) ([]tables.FollowUser, error) {
+	var authors []tables.FollowUser
+	const query = `
+		SELECT
+			u.user_id,
+			COALESCE(u.pen_name, u.username, '') AS display_name,
+			u.avatar_url,
+			af.create_datetime AS follow_datetime
+		FROM author_follows af
+		JOIN users u ON u.user_id = af.author_id
+		WHERE af.follower_id = $1
+		ORDER BY af.create_datetime DESC
+		LIMIT $2 OFFSET $3`
+
+	if err := pgxscan.Select(ctx, d.DB, &authors, query, followerID, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar penulis yang diikuti: %w", err)
+	}
+	return authors, nil
+}
+
+// NotifyFollowersOfNewBook mengirim notifikasi NEW_BOOK_BY_AUTHOR ke semua pengikut penulis.
+// Notifikasi disisipkan per batch berdasarkan follower_id (keyset), masing-masing dalam statement
+// sendiri, sehingga penulis dengan banyak pengikut tidak membuat satu transaksi raksasa.
+// Mengembalikan jumlah notifikasi yang berhasil dibuat.
+func (d *AuthorFollowDao) NotifyFollowersOfNewBook(ctx context.Context, authorID, bookID int64, content string, batchSize int) (int64, error) {
+	const query = `
+		WITH batch AS (
+			SELECT follower_id
+			FROM author_follows
+			WHERE author_id = $1 AND follower_id > $2
+			ORDER BY follower_id
+			LIMIT $3
+		), inserted AS (
+			INSERT INTO system_notifications (user_id, actor_id, notification_type, content, related_entity_type, related_entity_id)
+			SELECT follower_id, $1, 'NEW_BOOK_BY_AUTHOR', $4, 'BOOK', $5
+			FROM batch
+		)
+		SELECT COUNT(*), COALESCE(MAX(follower_id), 0) FROM batch`
+
+	var (
+		total      int64
+		lastUserID int64
+	)
+	for {
+		var count, maxUserID int64
+		if err := d.DB.QueryRow(ctx, query, authorID, lastUserID, batchSize, content, bookID).Scan(&count, &maxUserID); err != nil {
+			return total, fmt.Errorf("gagal mengirim notifikasi buku baru: %w", err)
+		}
+		total += count
+		if count < int64(batchSize) {
+			return total, nil
+		}
+		lastUserID = maxUserID
+	}
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BankDao menangani operasi database untuk tabel banks.
+type BankDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewBankDao(db *pgxpool.Pool) *BankDao {
+	return &BankDao{DB: db}
+}
+
+// GetActiveBank mengambil bank yang masih aktif, atau nil jika bank tidak ada atau sudah nonaktif.
+func (d *BankDao) GetActiveBank(ctx context.Context, bankID int64) (*tables.Bank, error) {
+	var bank tables.Bank
+	const query = `
+		SELECT
+			bank_id, bank_name, bank_code, remark, account_number_min_length, account_number_max_length,
+			active_datetime, non_active_datetime, create_datetime, update_datetime
+		FROM banks
+		WHERE bank_id = $1 AND active_datetime <= NOW() AND non_active_datetime IS NULL`
+	if err := pgxscan.Get(ctx, d.DB, &bank, query, bankID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil bank: %w", err)
+	}
+	return &bank, nil
+}
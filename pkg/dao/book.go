@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"errors"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/tables"
 
 	"github.com/Masterminds/squirrel"
@@ -22,7 +23,6 @@ func NewBookDao(db *pgxpool.Pool) *BookDao {
 }
 
 // CreateBook membuat entri buku baru beserta relasinya dalam satu transaksi.
-// (Fungsi ini tidak berubah)
 func (d *BookDao) CreateBook(ctx context.Context, bookData *tables.Book, authorID int64, genreIDs []int64) (*tables.Book, error) {
 	tx, err := d.DB.Begin(ctx)
 	if err != nil {
@@ -60,6 +60,14 @@ func (d *BookDao) CreateBook(ctx context.Context, bookData *tables.Book, authorI
 		return nil, err
 	}
 
+	// Status awal (Draft) menjadi titik pertama riwayat status buku
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO book_status_history (book_id, from_status, to_status, actor_id)
+		VALUES ($1, NULL, 'D', $2)`, newBookID, authorID); err != nil {
+		logrus.Errorf("Gagal INSERT ke tabel book_status_history: %v", err)
+		return nil, err
+	}
+
 	if len(genreIDs) > 0 {
 		insertBuilder := psql.Insert("book_genres").Columns("book_id", "genre_id")
 		for _, genreID := range genreIDs {
@@ -143,39 +151,6 @@ func (d *BookDao) GetBookWithAuthor(ctx context.Context, bookID int64) (*tables.
 	return &book, nil
 }
 
-// CountChaptersByBookID menghitung jumlah chapter yang ada untuk sebuah buku.
-func (d *BookDao) CountChaptersByBookID(ctx context.Context, bookID int64) (int, error) {
-	var count int
-	const query = `SELECT COUNT(*) FROM chapters WHERE book_id = $1`
-	err := d.DB.QueryRow(ctx, query, bookID).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-	return count, nil
-}
-
-// UpdateBookStatus mengubah status sebuah buku.
-func (d *BookDao) UpdateBookStatus(ctx context.Context, bookID int64, newStatus string) error {
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	sql, args, err := psql.Update("books").
-		Set("status", newStatus).
-		Where(squirrel.Eq{"book_id": bookID}).
-		ToSql()
-
-	if err != nil {
-		return err
-	}
-
-	cmdTag, err := d.DB.Exec(ctx, sql, args...)
-	if err != nil {
-		return err
-	}
-	if cmdTag.RowsAffected() != 1 {
-		return errors.New("buku tidak ditemukan atau status tidak berubah")
-	}
-	return nil
-}
-
 // GetBookDetailByID mengambil detail buku tunggal, lengkap dengan genre yang digabungkan.
 func (d *BookDao) GetBookDetailByID(ctx context.Context, bookID int64) (*tables.Book, error) {
     var book tables.Book
@@ -208,41 +183,81 @@ func (d *BookDao) GetBookDetailByID(ctx context.Context, bookID int64) (*tables.
 	return &book, nil
 }
 
-// GetPublishedBooks mengambil daftar buku yang statusnya bukan Draft dengan pagination.
-func (d *BookDao) GetPublishedBooks(ctx context.Context, limit, offset int) ([]tables.Book, error) {
-    var books []tables.Book
-    const query = `
-        SELECT
-            b.book_id, b.title, b.description, b.cover_image_url, b.status,
-            b.rating_average, b.total_views, b.create_datetime, b.update_datetime,
-            u.pen_name,
-            STRING_AGG(g.genre_name, ', ') as genres
-        FROM
-            books b
-        JOIN
-            author_books ab ON b.book_id = ab.book_id
-        JOIN
-            users u ON ab.user_id = u.user_id
-        LEFT JOIN
-            book_genres bg ON b.book_id = bg.book_id
-        LEFT JOIN
-            genres g ON bg.genre_id = g.genre_id
-        WHERE
-            b.status <> 'D' -- PERUBAHAN: Mengambil semua yang BUKAN Draft ('P', 'C', 'H')
-        GROUP BY
-            b.book_id, u.pen_name
-        ORDER BY
-            b.create_datetime DESC
-        LIMIT $1 OFFSET $2`
-
-    err := pgxscan.Select(ctx, d.DB, &books, query, limit, offset)
-    return books, err
+// GetPublishedBooks mengambil daftar buku yang statusnya bukan Draft sesuai filter dan urutan.
+// Halaman diambil dengan cursor keyset jika page.Cursor diisi, selain itu dengan offset.
+func (d *BookDao) GetPublishedBooks(ctx context.Context, filter BookSearchFilter, sort BookSort, page PageRequest) ([]tables.Book, cursor.Page, error) {
+	keyset, ok := bookSortKeysets[sort]
+	if !ok {
+		sort, keyset = BookSortNewest, bookSortKeysets[BookSortNewest]
+	}
+	builder, err := applyKeyset(filter.apply(publicBookListBuilder(), true), keyset.columns, page)
+	if err != nil {
+		return nil, cursor.Page{}, err
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, cursor.Page{}, err
+	}
+
+	var books []tables.Book
+	if err := pgxscan.Select(ctx, d.DB, &books, query, args...); err != nil {
+		return nil, cursor.Page{}, err
+	}
+	books, cursors := finishKeysetPage(books, page, sort.CursorKey(), keyset.values)
+	return books, cursors, nil
+}
+
+// CountPublishedBooks menghitung total buku yang statusnya bukan Draft dan cocok dengan filter.
+func (d *BookDao) CountPublishedBooks(ctx context.Context, filter BookSearchFilter) (int64, error) {
+	return d.countPublicBooks(ctx, filter)
+}
+
+// GetRecommendedBooks mengambil buku yang sudah dipublikasikan, diurutkan berdasarkan jumlah genre
+// yang cocok dengan genre favorit pengguna, lalu rating dan jumlah pembaca. Pengguna tanpa genre
+// favorit tetap mendapat daftar buku terpopuler.
+func (d *BookDao) GetRecommendedBooks(ctx context.Context, userID int64, limit, offset int) ([]tables.Book, error) {
+	var books []tables.Book
+	const query = `
+		SELECT
+			b.book_id, b.title, b.description, b.cover_image_url, b.status,
+			b.rating_average, b.total_views, b.create_datetime, b.update_datetime,
+			u.pen_name,
+			STRING_AGG(g.genre_name, ', ') as genres
+		FROM
+			books b
+		JOIN
+			author_books ab ON b.book_id = ab.book_id
+		JOIN
+			users u ON ab.user_id = u.user_id
+		LEFT JOIN
+			book_genres bg ON b.book_id = bg.book_id
+		LEFT JOIN
+			genres g ON bg.genre_id = g.genre_id
+		LEFT JOIN
+			user_genres ug ON ug.genre_id = bg.genre_id AND ug.user_id = $1
+		WHERE
+			b.status <> 'D'
+		GROUP BY
+			b.book_id, u.pen_name
+		ORDER BY
+			COUNT(ug.genre_id) DESC,
+			b.rating_average DESC,
+			b.total_views DESC,
+			b.create_datetime DESC
+		LIMIT $2 OFFSET $3`
+
+	err := pgxscan.Select(ctx, d.DB, &books, query, userID, limit, offset)
+	return books, err
 }
 
-// CountPublishedBooks menghitung total buku yang statusnya bukan Draft.
-func (d *BookDao) CountPublishedBooks(ctx context.Context) (int64, error) {
-    var count int64
-    const query = `SELECT COUNT(*) FROM books WHERE status <> 'D'` // PERUBAHAN
-    err := d.DB.QueryRow(ctx, query).Scan(&count)
-    return count, err
-}
\ No newline at end of file
+// MarkFirstPublished mengisi first_publish_datetime jika buku belum pernah dipublikasikan.
+// Mengembalikan true hanya pada publikasi pertama, dipakai untuk memicu notifikasi ke pengikut.
+func (d *BookDao) MarkFirstPublished(ctx context.Context, bookID int64) (bool, error) {
+	cmdTag, err := d.DB.Exec(ctx, `
+		UPDATE books SET first_publish_datetime = NOW()
+		WHERE book_id = $1 AND first_publish_datetime IS NULL`, bookID)
+	if err != nil {
+		return false, err
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
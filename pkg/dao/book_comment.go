@@ -2,11 +2,13 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"noversystem/pkg/tables"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
@@ -76,11 +78,26 @@ func (d *BookCommentDao) CreateCommentAndNotify(ctx context.Context, commentData
 	return commentData, nil
 }
 
-func (d *BookCommentDao) GetCommentsByBookID(ctx context.Context, bookID int64) ([]tables.BookComment, error) {
+// GetCommentsByBookID mengambil komentar sebuah buku. Jika viewerID diisi (bukan 0), komentar dari
+// pengguna yang diblokir atau dibisukan oleh viewer tidak ikut dikembalikan, begitu juga semua
+// balasan di bawahnya agar tidak muncul balasan tanpa induk.
+func (d *BookCommentDao) GetCommentsByBookID(ctx context.Context, bookID, viewerID int64) ([]tables.BookComment, error) {
 	var comments []tables.BookComment
 
 	// ✨ QUERY DIPERBARUI DENGAN LOGIKA KONDISIONAL
+	// visible menelusuri pohon komentar dari komentar utama ke balasannya, berhenti di komentar yang disembunyikan
 	query := `
+        WITH RECURSIVE visible AS (
+            SELECT c.comment_id, c.book_id, c.user_id, c.comment_text, c.parent_comment_id, c.create_datetime
+            FROM book_comments c
+            WHERE c.book_id = $1 AND c.parent_comment_id IS NULL
+              AND NOT EXISTS (SELECT 1 FROM user_blocks ub WHERE ub.blocker_id = $2 AND ub.blocked_id = c.user_id)
+            UNION ALL
+            SELECT c.comment_id, c.book_id, c.user_id, c.comment_text, c.parent_comment_id, c.create_datetime
+            FROM book_comments c
+            JOIN visible p ON c.parent_comment_id = p.comment_id
+            WHERE NOT EXISTS (SELECT 1 FROM user_blocks ub WHERE ub.blocker_id = $2 AND ub.blocked_id = c.user_id)
+        )
         SELECT
             bc.comment_id,
             bc.book_id,
@@ -95,21 +112,32 @@ func (d *BookCommentDao) GetCommentsByBookID(ctx context.Context, bookID int64)
                 ELSE u.full_name 
             END as pen_name
         FROM
-            book_comments bc
+            visible bc
         -- Join ke tabel users untuk mendapatkan detail komentator
         JOIN
             users u ON bc.user_id = u.user_id
         -- Join ke tabel author_books untuk mendapatkan ID penulis buku
         LEFT JOIN
             author_books ab ON bc.book_id = ab.book_id
-        WHERE
-            bc.book_id = $1
         ORDER BY
             bc.create_datetime ASC`
 
-	err := pgxscan.Select(ctx, d.DB, &comments, query, bookID)
+	err := pgxscan.Select(ctx, d.DB, &comments, query, bookID, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("gagal mengambil komentar buku: %w", err)
 	}
 	return comments, nil
 }
+
+// GetCommentOwner mengembalikan user_id pemilik komentar pada buku tertentu, atau 0 jika komentar tidak ditemukan.
+func (d *BookCommentDao) GetCommentOwner(ctx context.Context, commentID, bookID int64) (int64, error) {
+	var ownerID int64
+	err := d.DB.QueryRow(ctx, `SELECT user_id FROM book_comments WHERE comment_id = $1 AND book_id = $2`, commentID, bookID).Scan(&ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("gagal mengambil pemilik komentar: %w", err)
+	}
+	return ownerID, nil
+}
@@ -0,0 +1,225 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrBookNotFound dikembalikan jika buku yang diubah tidak ditemukan.
+var ErrBookNotFound = errors.New("buku tidak ditemukan")
+
+// BookMetadataUpdate berisi perubahan metadata buku. Field nil tidak diubah.
+type BookMetadataUpdate struct {
+	Title         *string
+	Description   *string // String kosong menghapus deskripsi
+	CoverImageURL *string // String kosong menghapus sampul
+	GenreIDs      []int64 // nil berarti genre tidak diubah
+}
+
+// UpdateBookMetadata menerapkan perubahan metadata buku dan mencatatnya di book_revisions dalam
+// satu transaksi. Jika tidak ada yang berbeda dari data saat ini, tidak ada yang disimpan dan
+// revisi yang dikembalikan bernilai nil.
+func (d *BookDao) UpdateBookMetadata(ctx context.Context, bookID, editorID int64, update BookMetadataUpdate) (*tables.BookRevision, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris buku agar dua perubahan bersamaan tercatat berurutan dengan snapshot yang benar
+	var before tables.BookSnapshot
+	err = tx.QueryRow(ctx, `SELECT title, description, cover_image_url FROM books WHERE book_id = $1 FOR UPDATE`, bookID).
+		Scan(&before.Title, &before.Description, &before.CoverImageURL)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		return nil, fmt.Errorf("gagal mengambil data buku: %w", err)
+	}
+	before.GenreIDs, before.GenreNames, err = bookGenreSnapshot(ctx, tx, `
+		SELECT g.genre_id, g.genre_name FROM book_genres bg
+		JOIN genres g ON g.genre_id = bg.genre_id
+		WHERE bg.book_id = $1
+		ORDER BY g.genre_id`, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	after := before
+	var changed []string
+	bookChanges := squirrel.Eq{}
+	if update.Title != nil && *update.Title != before.Title {
+		after.Title = *update.Title
+		changed = append(changed, tables.BookFieldTitle)
+		bookChanges["title"] = after.Title
+	}
+	if update.Description != nil && !sameOptionalText(before.Description, *update.Description) {
+		after.Description = optionalText(*update.Description)
+		changed = append(changed, tables.BookFieldDescription)
+		bookChanges["description"] = after.Description
+	}
+	if update.CoverImageURL != nil && !sameOptionalText(before.CoverImageURL, *update.CoverImageURL) {
+		after.CoverImageURL = optionalText(*update.CoverImageURL)
+		changed = append(changed, tables.BookFieldCoverImageURL)
+		bookChanges["cover_image_url"] = after.CoverImageURL
+	}
+	genresChanged := false
+	if update.GenreIDs != nil {
+		genreIDs := slices.Clone(update.GenreIDs)
+		slices.Sort(genreIDs)
+		genreIDs = slices.Compact(genreIDs)
+		if !slices.Equal(genreIDs, before.GenreIDs) {
+			after.GenreIDs, after.GenreNames, err = bookGenreSnapshot(ctx, tx, `
+				SELECT genre_id, genre_name FROM genres
+				WHERE genre_id = ANY($1::bigint[])
+				ORDER BY genre_id`, genreIDs)
+			if err != nil {
+				return nil, err
+			}
+			genresChanged = true
+			changed = append(changed, tables.BookFieldGenres)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	if len(bookChanges) > 0 {
+		sql, args, err := psql.Update("books").SetMap(bookChanges).Where(squirrel.Eq{"book_id": bookID}).ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyusun query ubah buku: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return nil, fmt.Errorf("gagal mengubah data buku: %w", err)
+		}
+	}
+	if genresChanged {
+		if _, err := tx.Exec(ctx, `DELETE FROM book_genres WHERE book_id = $1`, bookID); err != nil {
+			return nil, fmt.Errorf("gagal menghapus genre buku: %w", err)
+		}
+		insertBuilder := psql.Insert("book_genres").Columns("book_id", "genre_id")
+		for _, genreID := range after.GenreIDs {
+			insertBuilder = insertBuilder.Values(bookID, genreID)
+		}
+		sql, args, err := insertBuilder.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyusun query genre buku: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return nil, fmt.Errorf("gagal menyimpan genre buku: %w", err)
+		}
+		// Buku tetap dianggap berubah walau hanya genrenya yang diganti
+		if len(bookChanges) == 0 {
+			if _, err := tx.Exec(ctx, `UPDATE books SET update_datetime = NOW() WHERE book_id = $1`, bookID); err != nil {
+				return nil, fmt.Errorf("gagal mengubah data buku: %w", err)
+			}
+		}
+	}
+
+	beforeData, err := json.Marshal(before)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun snapshot buku: %w", err)
+	}
+	afterData, err := json.Marshal(after)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun snapshot buku: %w", err)
+	}
+	revision := &tables.BookRevision{
+		BookID:        bookID,
+		EditorID:      &editorID,
+		ChangedFields: changed,
+		Before:        before,
+		After:         after,
+	}
+	const insertQuery = `
+		INSERT INTO book_revisions (book_id, editor_id, changed_fields, before_data, after_data)
+		VALUES ($1, $2, $3::text[], $4::jsonb, $5::jsonb)
+		RETURNING revision_id, create_datetime`
+	err = tx.QueryRow(ctx, insertQuery, bookID, editorID, changed, string(beforeData), string(afterData)).
+		Scan(&revision.RevisionID, &revision.CreateDatetime)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencatat revisi buku: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return revision, nil
+}
+
+// GetBookRevisions mengambil riwayat revisi sebuah buku, terbaru lebih dulu.
+func (d *BookDao) GetBookRevisions(ctx context.Context, bookID int64, limit, offset int) ([]tables.BookRevision, error) {
+	var revisions []tables.BookRevision
+	const query = `
+		SELECT
+			r.revision_id, r.book_id, r.editor_id,
+			COALESCE(u.pen_name, u.full_name) AS editor_name,
+			r.changed_fields, r.before_data, r.after_data, r.create_datetime
+		FROM book_revisions r
+		LEFT JOIN users u ON u.user_id = r.editor_id
+		WHERE r.book_id = $1
+		ORDER BY r.create_datetime DESC, r.revision_id DESC
+		LIMIT $2 OFFSET $3`
+	if err := pgxscan.Select(ctx, d.DB, &revisions, query, bookID, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil riwayat revisi buku: %w", err)
+	}
+	return revisions, nil
+}
+
+// CountBookRevisions menghitung jumlah revisi sebuah buku.
+func (d *BookDao) CountBookRevisions(ctx context.Context, bookID int64) (int64, error) {
+	var count int64
+	if err := d.DB.QueryRow(ctx, `SELECT COUNT(*) FROM book_revisions WHERE book_id = $1`, bookID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("gagal menghitung revisi buku: %w", err)
+	}
+	return count, nil
+}
+
+// bookGenreSnapshot menjalankan query yang menghasilkan (genre_id, genre_name) untuk snapshot revisi.
+func bookGenreSnapshot(ctx context.Context, tx pgx.Tx, query string, arg interface{}) ([]int64, []string, error) {
+	rows, err := tx.Query(ctx, query, arg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal mengambil genre buku: %w", err)
+	}
+	defer rows.Close()
+
+	ids, names := []int64{}, []string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca genre buku: %w", err)
+		}
+		ids = append(ids, id)
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca genre buku: %w", err)
+	}
+	return ids, names, nil
+}
+
+// optionalText mengubah string kosong menjadi NULL untuk kolom teks opsional.
+func optionalText(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// sameOptionalText membandingkan kolom teks opsional dengan nilai baru, di mana string kosong sama dengan NULL.
+func sameOptionalText(current *string, value string) bool {
+	if current == nil {
+		return value == ""
+	}
+	return *current == value
+}
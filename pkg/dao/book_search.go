@@ -0,0 +1,204 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"noversystem/pkg/tables"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+// BookSearchFilter berisi kriteria daftar dan pencarian buku publik. Field kosong tidak membatasi hasil.
+type BookSearchFilter struct {
+	Query     string   // Kata kunci untuk judul, deskripsi, dan nama pena
+	GenreIDs  []int64  // Buku cocok jika memiliki salah satu genre
+	Statuses  []string // Subset dari P, C, H. Kosong berarti semua status selain Draft
+	MinRating *float64
+	FreeOnly  bool // Hanya buku yang semua chapter terbitnya gratis
+}
+
+// BookSort adalah urutan yang tersedia untuk daftar buku publik.
+type BookSort string
+
+const (
+	BookSortNewest          BookSort = "newest"
+	BookSortMostViewed      BookSort = "most_viewed"
+	BookSortTopRated        BookSort = "top_rated"
+	BookSortRecentlyUpdated BookSort = "recently_updated"
+)
+
+// bookSortKeyset adalah kolom urutan keyset sebuah BookSort beserta cara membaca nilainya dari baris.
+type bookSortKeyset struct {
+	columns []keysetColumn
+	values  func(tables.Book) []string
+}
+
+// bookSortKeysets memetakan BookSort ke kolom urutannya. book_id selalu menjadi pemutus seri
+// agar urutan antar halaman stabil. Setiap urutan didukung index di migration daftar buku.
+var bookSortKeysets = map[BookSort]bookSortKeyset{
+	BookSortNewest: {
+		columns: []keysetColumn{{"b.create_datetime", "timestamptz"}, {"b.book_id", "bigint"}},
+		values: func(b tables.Book) []string {
+			return []string{keysetTime(b.CreateDatetime), keysetInt(b.BookID)}
+		},
+	},
+	BookSortMostViewed: {
+		columns: []keysetColumn{{"b.total_views", "bigint"}, {"b.book_id", "bigint"}},
+		values: func(b tables.Book) []string {
+			return []string{keysetInt(b.TotalViews), keysetInt(b.BookID)}
+		},
+	},
+	BookSortTopRated: {
+		columns: []keysetColumn{{"b.rating_average", "numeric"}, {"b.total_views", "bigint"}, {"b.book_id", "bigint"}},
+		values: func(b tables.Book) []string {
+			return []string{strconv.FormatFloat(b.RatingAverage, 'f', -1, 64), keysetInt(b.TotalViews), keysetInt(b.BookID)}
+		},
+	},
+	BookSortRecentlyUpdated: {
+		columns: []keysetColumn{{"COALESCE(b.update_datetime, b.create_datetime)", "timestamptz"}, {"b.book_id", "bigint"}},
+		values: func(b tables.Book) []string {
+			updated := b.CreateDatetime
+			if b.UpdateDatetime != nil {
+				updated = *b.UpdateDatetime
+			}
+			return []string{keysetTime(updated), keysetInt(b.BookID)}
+		},
+	},
+}
+
+// IsValid memeriksa apakah urutan dikenali.
+func (s BookSort) IsValid() bool {
+	_, ok := bookSortKeysets[s]
+	return ok
+}
+
+// CursorKey adalah nama daftar yang disimpan di cursor, sehingga cursor satu urutan tidak bisa
+// dipakai untuk urutan lain.
+func (s BookSort) CursorKey() string {
+	return "books:" + string(s)
+}
+
+// bookSearchQuery adalah ekspresi tsquery untuk kata kunci pencarian.
+const bookSearchQuery = `websearch_to_tsquery('nover_id', ?)`
+
+// apply menambahkan kondisi filter ke query yang sudah menggabungkan books b dan users u (penulis).
+// Filter genre bisa dilewati agar facet genre tetap menghitung genre lain di luar pilihan pengguna.
+func (f BookSearchFilter) apply(builder squirrel.SelectBuilder, withGenres bool) squirrel.SelectBuilder {
+	if len(f.Statuses) > 0 {
+		builder = builder.Where("b.status::text = ANY(?::text[])", f.Statuses)
+	} else {
+		builder = builder.Where("b.status <> 'D'")
+	}
+	if f.MinRating != nil {
+		builder = builder.Where("b.rating_average >= ?", *f.MinRating)
+	}
+	if f.FreeOnly {
+		builder = builder.Where("NOT EXISTS (SELECT 1 FROM chapters fc WHERE fc.book_id = b.book_id AND fc.status = 'P' AND fc.coin_cost > 0)")
+	}
+	if withGenres && len(f.GenreIDs) > 0 {
+		builder = builder.Where("EXISTS (SELECT 1 FROM book_genres fbg WHERE fbg.book_id = b.book_id AND fbg.genre_id = ANY(?::bigint[]))", f.GenreIDs)
+	}
+	if f.Query != "" {
+		// Full-text untuk judul/deskripsi, ditambah trigram agar salah ketik pada judul tetap ketemu.
+		// Nama pena cukup dicocokkan dengan trigram karena hanya itu yang punya index (idx_users_pen_name_trgm).
+		builder = builder.Where(squirrel.Or{
+			squirrel.Expr("b.search_vector @@ "+bookSearchQuery, f.Query),
+			squirrel.Expr("nover_normalize(?) <% nover_normalize(b.title)", f.Query),
+			squirrel.Expr("nover_normalize(?) <% nover_normalize(u.pen_name)", f.Query),
+		})
+	}
+	return builder
+}
+
+// publicBookListBuilder menyusun SELECT buku publik beserta nama pena penulis dan daftar genrenya.
+func publicBookListBuilder() squirrel.SelectBuilder {
+	return squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select(
+			"b.book_id", "b.title", "b.description", "b.cover_image_url", "b.status",
+			"b.rating_average", "b.total_views", "b.create_datetime", "b.update_datetime",
+			"u.pen_name",
+			"(SELECT STRING_AGG(g.genre_name, ', ') FROM book_genres bg JOIN genres g ON g.genre_id = bg.genre_id WHERE bg.book_id = b.book_id) AS genres",
+		).
+		From("books b").
+		Join("author_books ab ON b.book_id = ab.book_id").
+		Join("users u ON ab.user_id = u.user_id")
+}
+
+// countPublicBooks menghitung buku publik yang cocok dengan filter.
+func (d *BookDao) countPublicBooks(ctx context.Context, filter BookSearchFilter) (int64, error) {
+	builder := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Select("COUNT(DISTINCT b.book_id)").
+		From("books b").
+		Join("author_books ab ON b.book_id = ab.book_id").
+		Join("users u ON ab.user_id = u.user_id")
+	query, args, err := filter.apply(builder, true).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("gagal menyusun query hitung buku: %w", err)
+	}
+
+	var total int64
+	if err := d.DB.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("gagal menghitung buku: %w", err)
+	}
+	return total, nil
+}
+
+// SearchBooks mencari buku publik sesuai filter. Jika ada kata kunci, hasil diurutkan berdasarkan
+// relevansi; jika tidak, buku terbaru lebih dulu.
+func (d *BookDao) SearchBooks(ctx context.Context, filter BookSearchFilter, limit, offset int) ([]tables.Book, error) {
+	builder := filter.apply(publicBookListBuilder(), true)
+
+	if filter.Query != "" {
+		builder = builder.OrderByClause(
+			"ts_rank(b.search_vector, "+bookSearchQuery+") + word_similarity(nover_normalize(?), nover_normalize(b.title)) DESC",
+			filter.Query, filter.Query,
+		)
+	}
+	builder = builder.OrderBy("b.create_datetime DESC", "b.book_id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun query pencarian buku: %w", err)
+	}
+
+	var books []tables.Book
+	if err := pgxscan.Select(ctx, d.DB, &books, query, args...); err != nil {
+		return nil, fmt.Errorf("gagal mencari buku: %w", err)
+	}
+	return books, nil
+}
+
+// CountSearchBooks menghitung jumlah buku yang cocok dengan filter.
+func (d *BookDao) CountSearchBooks(ctx context.Context, filter BookSearchFilter) (int64, error) {
+	return d.countPublicBooks(ctx, filter)
+}
+
+// GetSearchGenreFacets menghitung jumlah buku per genre untuk filter yang sama, tanpa filter genre,
+// sehingga pengguna bisa melihat berapa hasil yang didapat jika memilih genre lain.
+func (d *BookDao) GetSearchGenreFacets(ctx context.Context, filter BookSearchFilter) ([]tables.GenreFacet, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	builder := psql.Select("g.genre_id", "g.genre_name", "COUNT(DISTINCT b.book_id) AS book_count").
+		From("books b").
+		Join("author_books ab ON b.book_id = ab.book_id").
+		Join("users u ON ab.user_id = u.user_id").
+		Join("book_genres bg ON bg.book_id = b.book_id").
+		Join("genres g ON g.genre_id = bg.genre_id")
+	query, args, err := filter.apply(builder, false).
+		GroupBy("g.genre_id", "g.genre_name").
+		OrderBy("book_count DESC", "g.genre_name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyusun query facet genre: %w", err)
+	}
+
+	var facets []tables.GenreFacet
+	if err := pgxscan.Select(ctx, d.DB, &facets, query, args...); err != nil {
+		return nil, fmt.Errorf("gagal menghitung facet genre: %w", err)
+	}
+	return facets, nil
+}
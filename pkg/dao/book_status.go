@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"slices"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrBookHasNoChapters dikembalikan oleh syarat transisi yang membutuhkan minimal satu chapter terbit.
+var ErrBookHasNoChapters = errors.New("buku belum memiliki chapter terbit")
+
+// BookStatusTransitionError dikembalikan jika perpindahan status tidak ada di tabel transisi.
+type BookStatusTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *BookStatusTransitionError) Error() string {
+	return fmt.Sprintf("status buku tidak bisa diubah dari %s ke %s", e.From, e.To)
+}
+
+// bookStatusGuard adalah syarat tambahan sebuah transisi. Syarat dijalankan di dalam transaksi yang
+// sama dengan perubahan status, setelah baris buku dikunci.
+type bookStatusGuard func(ctx context.Context, tx pgx.Tx, bookID int64) error
+
+// bookStatusTransitions adalah satu-satunya sumber aturan perpindahan status buku:
+// status asal -> status tujuan -> syarat. Perpindahan yang tidak ada di sini ditolak.
+var bookStatusTransitions = map[string]map[string][]bookStatusGuard{
+	tables.BookStatusDraft: {
+		tables.BookStatusPublished: {requireChapters},
+	},
+	tables.BookStatusPublished: {
+		tables.BookStatusDraft:     nil,
+		tables.BookStatusCompleted: nil,
+		tables.BookStatusOnHold:    nil,
+	},
+	tables.BookStatusOnHold: {
+		tables.BookStatusPublished: {requireChapters},
+		tables.BookStatusCompleted: nil,
+		tables.BookStatusDraft:     nil,
+	},
+	tables.BookStatusCompleted: {
+		tables.BookStatusPublished: nil, // Dibuka kembali untuk melanjutkan cerita
+	},
+}
+
+// AllowedBookStatusTransitions mengembalikan status tujuan yang boleh dicapai dari sebuah status.
+func AllowedBookStatusTransitions(from string) []string {
+	targets := make([]string, 0, len(bookStatusTransitions[from]))
+	for to := range bookStatusTransitions[from] {
+		targets = append(targets, to)
+	}
+	slices.Sort(targets)
+	return targets
+}
+
+// requireChapters memastikan buku memiliki minimal satu chapter terbit sebelum tampil ke pembaca.
+// Chapter diterbitkan lewat ChapterDao.PublishChapter; chapter buku lama yang sudah tampil ke pembaca
+// ditandai terbit oleh migrasi 20261018030000_publish_existing_chapters.
+func requireChapters(ctx context.Context, tx pgx.Tx, bookID int64) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM chapters WHERE book_id = $1 AND status = 'P')`, bookID).Scan(&exists); err != nil {
+		return fmt.Errorf("gagal memeriksa chapter buku: %w", err)
+	}
+	if !exists {
+		return ErrBookHasNoChapters
+	}
+	return nil
+}
+
+// TransitionBookStatus memindahkan status buku sesuai tabel transisi dan mencatatnya di
+// book_status_history. Mengembalikan *BookStatusTransitionError jika perpindahan tidak diizinkan,
+// atau error dari syarat transisi (misalnya ErrBookHasNoChapters).
+func (d *BookDao) TransitionBookStatus(ctx context.Context, bookID, actorID int64, to, reason string) (*tables.BookStatusChange, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris buku agar dua perubahan status bersamaan diperiksa terhadap status terbaru
+	var from string
+	err = tx.QueryRow(ctx, `SELECT status::text FROM books WHERE book_id = $1 FOR UPDATE`, bookID).Scan(&from)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		return nil, fmt.Errorf("gagal mengambil status buku: %w", err)
+	}
+
+	guards, allowed := bookStatusTransitions[from][to]
+	if !allowed {
+		return nil, &BookStatusTransitionError{From: from, To: to}
+	}
+	for _, guard := range guards {
+		if err := guard(ctx, tx, bookID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE books SET status = $1::book_status WHERE book_id = $2`, to, bookID); err != nil {
+		return nil, fmt.Errorf("gagal mengubah status buku: %w", err)
+	}
+
+	change := &tables.BookStatusChange{BookID: bookID, FromStatus: &from, ToStatus: to, ActorID: &actorID}
+	if reason != "" {
+		change.Reason = &reason
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO book_status_history (book_id, from_status, to_status, actor_id, reason)
+		VALUES ($1, $2::book_status, $3::book_status, $4, $5)
+		RETURNING history_id, create_datetime`,
+		bookID, from, to, actorID, change.Reason,
+	).Scan(&change.HistoryID, &change.CreateDatetime)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mencatat riwayat status buku: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return change, nil
+}
+
+// GetBookStatusHistory mengambil riwayat status sebuah buku, terbaru lebih dulu.
+func (d *BookDao) GetBookStatusHistory(ctx context.Context, bookID int64, limit, offset int) ([]tables.BookStatusChange, error) {
+	var history []tables.BookStatusChange
+	const query = `
+		SELECT
+			h.history_id, h.book_id, h.from_status::text AS from_status, h.to_status::text AS to_status,
+			h.actor_id, COALESCE(u.pen_name, u.full_name) AS actor_name,
+			h.reason, h.create_datetime
+		FROM book_status_history h
+		LEFT JOIN users u ON u.user_id = h.actor_id
+		WHERE h.book_id = $1
+		ORDER BY h.create_datetime DESC, h.history_id DESC
+		LIMIT $2 OFFSET $3`
+	if err := pgxscan.Select(ctx, d.DB, &history, query, bookID, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil riwayat status buku: %w", err)
+	}
+	return history, nil
+}
+
+// CountBookStatusHistory menghitung jumlah riwayat status sebuah buku.
+func (d *BookDao) CountBookStatusHistory(ctx context.Context, bookID int64) (int64, error) {
+	var count int64
+	if err := d.DB.QueryRow(ctx, `SELECT COUNT(*) FROM book_status_history WHERE book_id = $1`, bookID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("gagal menghitung riwayat status buku: %w", err)
+	}
+	return count, nil
+}
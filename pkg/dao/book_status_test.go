@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"noversystem/pkg/tables"
+	"slices"
+	"testing"
+)
+
+func TestAllowedBookStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from string
+		want []string
+	}{
+		{tables.BookStatusDraft, []string{tables.BookStatusPublished}},
+		{tables.BookStatusPublished, []string{tables.BookStatusCompleted, tables.BookStatusDraft, tables.BookStatusOnHold}},
+		{tables.BookStatusOnHold, []string{tables.BookStatusCompleted, tables.BookStatusDraft, tables.BookStatusPublished}},
+		{tables.BookStatusCompleted, []string{tables.BookStatusPublished}},
+		{"X", []string{}},
+	}
+	for _, tt := range tests {
+		got := AllowedBookStatusTransitions(tt.from)
+		want := slices.Clone(tt.want)
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("AllowedBookStatusTransitions(%q) = %v, want %v", tt.from, got, want)
+		}
+	}
+}
+
+func TestBookStatusTransitionsRejected(t *testing.T) {
+	rejected := [][2]string{
+		{tables.BookStatusDraft, tables.BookStatusDraft},
+		{tables.BookStatusDraft, tables.BookStatusCompleted},
+		{tables.BookStatusDraft, tables.BookStatusOnHold},
+		{tables.BookStatusPublished, tables.BookStatusPublished},
+		{tables.BookStatusOnHold, tables.BookStatusOnHold},
+		{tables.BookStatusCompleted, tables.BookStatusCompleted},
+		{tables.BookStatusCompleted, tables.BookStatusDraft},
+		{tables.BookStatusCompleted, tables.BookStatusOnHold},
+	}
+	for _, pair := range rejected {
+		if _, ok := bookStatusTransitions[pair[0]][pair[1]]; ok {
+			t.Errorf("transisi %s -> %s seharusnya ditolak", pair[0], pair[1])
+		}
+	}
+}
+
+func TestBookStatusTransitionsRequireChaptersToPublish(t *testing.T) {
+	// Buku draft atau ditunda hanya boleh tampil ke pembaca jika sudah punya chapter terbit.
+	// Buku selesai yang dibuka kembali sudah pernah terbit sehingga tidak diperiksa lagi.
+	tests := []struct {
+		from, to   string
+		wantGuards int
+	}{
+		{tables.BookStatusDraft, tables.BookStatusPublished, 1},
+		{tables.BookStatusOnHold, tables.BookStatusPublished, 1},
+		{tables.BookStatusCompleted, tables.BookStatusPublished, 0},
+		{tables.BookStatusPublished, tables.BookStatusDraft, 0},
+		{tables.BookStatusPublished, tables.BookStatusCompleted, 0},
+		{tables.BookStatusPublished, tables.BookStatusOnHold, 0},
+		{tables.BookStatusOnHold, tables.BookStatusCompleted, 0},
+		{tables.BookStatusOnHold, tables.BookStatusDraft, 0},
+	}
+	for _, tt := range tests {
+		guards, ok := bookStatusTransitions[tt.from][tt.to]
+		if !ok {
+			t.Errorf("transisi %s -> %s seharusnya diizinkan", tt.from, tt.to)
+			continue
+		}
+		if len(guards) != tt.wantGuards {
+			t.Errorf("transisi %s -> %s punya %d syarat, want %d", tt.from, tt.to, len(guards), tt.wantGuards)
+		}
+	}
+}
+
+func TestBookStatusTransitionErrorMessage(t *testing.T) {
+	err := &BookStatusTransitionError{From: tables.BookStatusDraft, To: tables.BookStatusCompleted}
+	if got, want := err.Error(), "status buku tidak bisa diubah dari D ke C"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -48,13 +48,22 @@ func (d *ChapterDao) GetChaptersByBookID(ctx context.Context, bookID int64, isPu
     var chapters []tables.Chapter
 
     psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+    // Untuk tampilan publik, isi chapter tidak ikut dikirim (dibaca lewat /chapters/:chapterId
+    // yang memeriksa status berbayar) dan chapter draft disembunyikan.
+    contentColumn := "content"
+    if isPublic {
+        contentColumn = "NULL::text AS content"
+    }
     queryBuilder := psql.Select(
-        "chapter_id", "book_id", "title", "content", "chapter_order", 
+        "chapter_id", "book_id", "title", contentColumn, "chapter_order", 
         "status", "coin_cost", "total_views", "create_datetime", "update_datetime",
     ).
     From("chapters").
     Where(squirrel.Eq{"book_id": bookID}).
     OrderBy("chapter_order ASC")  // Urut tetap berdasarkan order
+    if isPublic {
+        queryBuilder = queryBuilder.Where(squirrel.Eq{"status": "P"})
+    }
 
     sql, args, err := queryBuilder.ToSql()
     if err != nil {
@@ -68,10 +77,23 @@ func (d *ChapterDao) GetChaptersByBookID(ctx context.Context, bookID int64, isPu
     return chapters, nil
 }
 
+// PublishChapter mengubah status chapter sebuah buku menjadi terbit ('P'). Mengembalikan nil jika
+// chapter tidak ditemukan di buku tersebut. Chapter yang sudah terbit dikembalikan apa adanya.
+func (d *ChapterDao) PublishChapter(ctx context.Context, bookID, chapterID int64) (*tables.Chapter, error) {
+    var chapter tables.Chapter
+    const query = `UPDATE chapters SET status = 'P' WHERE chapter_id = $1 AND book_id = $2 RETURNING *`
+    err := pgxscan.Get(ctx, d.DB, &chapter, query, chapterID, bookID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
+        return nil, err
+    }
+    return &chapter, nil
+}
+
 // ... (Fungsi GetPublishedChapterByID dan IsChapterUnlockedByUser tetap sama) ...
 func (d *ChapterDao) GetPublishedChapterByID(ctx context.Context, chapterID int64) (*tables.Chapter, error) {
     var chapter tables.Chapter
-    const query = `SELECT * FROM chapters WHERE chapter_id = $1`
+    const query = `SELECT * FROM chapters WHERE chapter_id = $1 AND status = 'P'`
     err := pgxscan.Get(ctx, d.DB, &chapter, query, chapterID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"fmt"
+	"noversystem/pkg/cursor"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// PageRequest adalah permintaan satu halaman daftar. Jika Cursor diisi, halaman diambil secara
+// keyset dari posisi cursor dan Offset diabaikan.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor *cursor.Cursor
+}
+
+// keysetColumn adalah satu kolom urutan keyset. Semua kolom diurutkan DESC pada arah normal,
+// dan kolom terakhir harus unik (ID) agar posisi cursor tidak ambigu.
+type keysetColumn struct {
+	expr string // Kolom atau ekspresi di query
+	cast string // Tipe Postgres nilai cursor: timestamptz, bigint, atau numeric
+}
+
+// applyKeyset menambahkan kondisi cursor, ORDER BY, dan LIMIT ke builder. Satu baris ekstra diambil
+// untuk mengetahui apakah masih ada halaman berikutnya; lihat finishKeysetPage.
+func applyKeyset(builder squirrel.SelectBuilder, columns []keysetColumn, req PageRequest) (squirrel.SelectBuilder, error) {
+	direction := " DESC"
+	if req.Cursor != nil {
+		if len(req.Cursor.Values) != len(columns) {
+			return builder, cursor.ErrInvalid
+		}
+		exprs := make([]string, len(columns))
+		placeholders := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			if !validKeysetValue(column.cast, req.Cursor.Values[i]) {
+				return builder, cursor.ErrInvalid
+			}
+			exprs[i] = column.expr
+			placeholders[i] = "?::" + column.cast
+			args[i] = req.Cursor.Values[i]
+		}
+
+		operator := "<"
+		if req.Cursor.Backward {
+			// Halaman sebelumnya dibaca mundur dari posisi cursor, lalu dibalik oleh finishKeysetPage
+			operator = ">"
+			direction = " ASC"
+		}
+		builder = builder.Where(fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), operator, strings.Join(placeholders, ", ")), args...)
+	} else if req.Offset > 0 {
+		builder = builder.Offset(uint64(req.Offset))
+	}
+
+	for _, column := range columns {
+		builder = builder.OrderBy(column.expr + direction)
+	}
+	return builder.Limit(uint64(req.Limit) + 1), nil
+}
+
+// validKeysetValue memastikan nilai dari cursor bisa di-cast ke tipe kolomnya, sehingga token yang
+// diubah klien ditolak sebagai cursor tidak valid alih-alih menjadi error database.
+func validKeysetValue(cast, value string) bool {
+	var err error
+	switch cast {
+	case "timestamptz":
+		_, err = time.Parse(time.RFC3339Nano, value)
+	case "bigint":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "numeric":
+		_, err = strconv.ParseFloat(value, 64)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+// finishKeysetPage memotong baris ekstra dari applyKeyset, mengembalikan urutan normal untuk
+// halaman mundur, dan membuat cursor ke halaman berikutnya dan sebelumnya.
+func finishKeysetPage[T any](rows []T, req PageRequest, key string, values func(T) []string) ([]T, cursor.Page) {
+	backward := req.Cursor != nil && req.Cursor.Backward
+	hasMore := len(rows) > req.Limit
+	if hasMore {
+		rows = rows[:req.Limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	var page cursor.Page
+	if len(rows) == 0 {
+		// Halaman kosong: tetap sediakan jalan kembali dari posisi cursor yang sama
+		if req.Cursor != nil {
+			same := cursor.Cursor{Key: key, Values: req.Cursor.Values}
+			if backward {
+				page.Next = same.Encode()
+			} else {
+				same.Backward = true
+				page.Prev = same.Encode()
+			}
+		}
+		return rows, page
+	}
+
+	first := cursor.Cursor{Key: key, Values: values(rows[0]), Backward: true}
+	last := cursor.Cursor{Key: key, Values: values(rows[len(rows)-1])}
+	if backward {
+		page.Next = last.Encode()
+		if hasMore {
+			page.Prev = first.Encode()
+		}
+	} else {
+		if hasMore {
+			page.Next = last.Encode()
+		}
+		if req.Cursor != nil || req.Offset > 0 {
+			page.Prev = first.Encode()
+		}
+	}
+	return rows, page
+}
+
+// keysetTime memformat timestamp untuk disimpan di cursor tanpa kehilangan presisi mikrodetik.
+func keysetTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// keysetInt memformat ID atau angka bulat untuk disimpan di cursor.
+func keysetInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,118 @@
+package dao
+
+import (
+	"noversystem/pkg/cursor"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestFinishKeysetPage(t *testing.T) {
+	const key = "test"
+	values := func(id int) []string { return []string{strconv.Itoa(id)} }
+	at := func(id int, backward bool) *cursor.Cursor {
+		return &cursor.Cursor{Key: key, Values: values(id), Backward: backward}
+	}
+
+	tests := []struct {
+		name     string
+		rows     []int // Baris dari database, termasuk baris ekstra dari applyKeyset
+		req      PageRequest
+		wantRows []int
+		wantNext *cursor.Cursor
+		wantPrev *cursor.Cursor
+	}{
+		{
+			name:     "first page with more rows",
+			rows:     []int{9, 8, 7},
+			req:      PageRequest{Limit: 2},
+			wantRows: []int{9, 8},
+			wantNext: at(8, false),
+		},
+		{
+			name:     "only page",
+			rows:     []int{9, 8},
+			req:      PageRequest{Limit: 2},
+			wantRows: []int{9, 8},
+		},
+		{
+			name: "empty list",
+			req:  PageRequest{Limit: 2},
+		},
+		{
+			name:     "offset page links back",
+			rows:     []int{7, 6},
+			req:      PageRequest{Limit: 2, Offset: 2},
+			wantRows: []int{7, 6},
+			wantPrev: at(7, true),
+		},
+		{
+			name:     "middle page forward",
+			rows:     []int{7, 6, 5},
+			req:      PageRequest{Limit: 2, Cursor: at(8, false)},
+			wantRows: []int{7, 6},
+			wantNext: at(6, false),
+			wantPrev: at(7, true),
+		},
+		{
+			name:     "last page forward",
+			rows:     []int{7},
+			req:      PageRequest{Limit: 2, Cursor: at(8, false)},
+			wantRows: []int{7},
+			wantPrev: at(7, true),
+		},
+		{
+			name:     "middle page backward",
+			rows:     []int{5, 6, 7}, // Dibaca ASC dari posisi cursor
+			req:      PageRequest{Limit: 2, Cursor: at(4, true)},
+			wantRows: []int{6, 5},
+			wantNext: at(5, false),
+			wantPrev: at(6, true),
+		},
+		{
+			name:     "backward to first page",
+			rows:     []int{8, 9},
+			req:      PageRequest{Limit: 2, Cursor: at(7, true)},
+			wantRows: []int{9, 8},
+			wantNext: at(8, false),
+		},
+		{
+			name:     "empty page after forward cursor",
+			req:      PageRequest{Limit: 2, Cursor: at(1, false)},
+			wantPrev: at(1, true),
+		},
+		{
+			name:     "empty page after backward cursor",
+			req:      PageRequest{Limit: 2, Cursor: at(9, true)},
+			wantNext: at(9, false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, page := finishKeysetPage(tt.rows, tt.req, key, values)
+			if !slices.Equal(rows, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, tt.wantRows)
+			}
+			assertCursor(t, "next", page.Next, key, tt.wantNext)
+			assertCursor(t, "prev", page.Prev, key, tt.wantPrev)
+		})
+	}
+}
+
+func assertCursor(t *testing.T, name, token, key string, want *cursor.Cursor) {
+	t.Helper()
+	if want == nil {
+		if token != "" {
+			t.Errorf("%s = %q, want empty", name, token)
+		}
+		return
+	}
+	got, err := cursor.Decode(token, key)
+	if err != nil || got == nil {
+		t.Fatalf("%s cursor %q cannot be decoded: %v", name, token, err)
+	}
+	if !reflect.DeepEqual(*got, *want) {
+		t.Errorf("%s = %+v, want %+v", name, *got, *want)
+	}
+}
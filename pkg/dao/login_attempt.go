@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginAttemptDao menangani operasi database untuk tabel login_attempts.
+type LoginAttemptDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewLoginAttemptDao(db *pgxpool.Pool) *LoginAttemptDao {
+	return &LoginAttemptDao{DB: db}
+}
+
+// RecordAttempt menyimpan satu percobaan login ke jejak audit.
+func (d *LoginAttemptDao) RecordAttempt(ctx context.Context, attempt *tables.LoginAttempt) error {
+	const query = `
+		INSERT INTO login_attempts (identifier, user_id, ip_address, user_agent, success, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := d.DB.Exec(ctx, query,
+		attempt.Identifier,
+		attempt.UserID,
+		attempt.IPAddress,
+		attempt.UserAgent,
+		attempt.Success,
+		attempt.FailureReason,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat percobaan login: %w", err)
+	}
+	return nil
+}
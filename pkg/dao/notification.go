@@ -3,8 +3,10 @@ package dao
 import (
 	"context"
 	"fmt"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/tables"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -17,63 +19,75 @@ func NewNotificationDao(db *pgxpool.Pool) *NotificationDao {
 	return &NotificationDao{DB: db}
 }
 
-// GetNotificationsByUserID mengambil daftar notifikasi untuk pengguna dengan pagination.
-func (d *NotificationDao) GetNotificationsByUserID(ctx context.Context, userID int64, limit, offset int) ([]tables.NotificationResponse, error) {
-	var notifications []tables.NotificationResponse
-	
-	// ✨ QUERY DEFINITIF YANG SUDAH DIPERBAIKI TOTAL
-	query := `
-		SELECT
-			sn.notification_id,
-			sn.is_read,
-			sn.create_datetime,
-			sn.notification_type,
-			sn.related_entity_id,
-			
-			COALESCE(actor.pen_name, actor.full_name, 'Anonymous') AS actor_name,
-			actor.avatar_url AS actor_avatar_url,
-			
-			COALESCE(b1.title, b2.title, b3.title, b4.title) AS book_name,
-			COALESCE(b1.book_id, b2.book_id, b3.book_id, b4.book_id) AS book_id,
-			COALESCE(c1.title, c2.title) AS chapter_name,
-			COALESCE(bc.comment_text, cc.comment_text) AS comment_content
-		FROM 
-			system_notifications sn
-		LEFT JOIN 
-			users actor ON sn.actor_id = actor.user_id
-		LEFT JOIN 
-			book_comments bc ON sn.related_entity_id = bc.comment_id AND sn.related_entity_type = 'BOOK_COMMENT' -- Lebih spesifik
-		LEFT JOIN 
-			books b1 ON bc.book_id = b1.book_id
-		LEFT JOIN 
-			chapter_comments cc ON sn.related_entity_id = cc.comment_id AND sn.related_entity_type = 'CHAPTER_COMMENT' -- Lebih spesifik
-		LEFT JOIN 
-			chapters c1 ON cc.chapter_id = c1.chapter_id
-		LEFT JOIN 
-			books b2 ON c1.book_id = b2.book_id
-		LEFT JOIN 
-			chapters c2 ON sn.related_entity_id = c2.chapter_id AND sn.related_entity_type = 'CHAPTER'
-		LEFT JOIN 
-			books b3 ON c2.book_id = b3.book_id
-		LEFT JOIN
-			books b4 ON sn.related_entity_id = b4.book_id AND sn.related_entity_type = 'BOOK'
-		WHERE 
-			sn.user_id = $1
-		ORDER BY 
-			sn.create_datetime DESC
-		LIMIT $2 OFFSET $3`
+// notificationKeyset adalah urutan keyset daftar notifikasi, terbaru lebih dulu.
+var notificationKeyset = []keysetColumn{{"sn.create_datetime", "timestamptz"}, {"sn.notification_id", "bigint"}}
+
+// NotificationCursorKey adalah nama daftar notifikasi di cursor.
+const NotificationCursorKey = "notifications"
 
-	err := pgxscan.Select(ctx, d.DB, &notifications, query, userID, limit, offset)
+// GetNotificationsByUserID mengambil daftar notifikasi untuk pengguna dengan pagination offset atau cursor.
+// Notifikasi yang dipicu aktor yang diblokir atau dibisukan pengguna disaring saat query, sehingga
+// muncul kembali jika blokir dibatalkan.
+func (d *NotificationDao) GetNotificationsByUserID(ctx context.Context, userID int64, page PageRequest) ([]tables.NotificationResponse, cursor.Page, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	builder := psql.Select(
+		"sn.notification_id",
+		"sn.is_read",
+		"sn.create_datetime",
+		"sn.notification_type",
+		"sn.content",
+		"sn.related_entity_id",
+		"COALESCE(actor.pen_name, actor.full_name, 'Anonymous') AS actor_name",
+		"actor.avatar_url AS actor_avatar_url",
+		"COALESCE(b1.title, b2.title, b3.title, b4.title) AS book_name",
+		"COALESCE(b1.book_id, b2.book_id, b3.book_id, b4.book_id) AS book_id",
+		"COALESCE(c1.title, c2.title) AS chapter_name",
+		"COALESCE(bc.comment_text, cc.comment_text) AS comment_content",
+	).
+		From("system_notifications sn").
+		LeftJoin("users actor ON sn.actor_id = actor.user_id").
+		LeftJoin("book_comments bc ON sn.related_entity_id = bc.comment_id AND sn.related_entity_type = 'BOOK_COMMENT'").
+		LeftJoin("books b1 ON bc.book_id = b1.book_id").
+		LeftJoin("chapter_comments cc ON sn.related_entity_id = cc.comment_id AND sn.related_entity_type = 'CHAPTER_COMMENT'").
+		LeftJoin("chapters c1 ON cc.chapter_id = c1.chapter_id").
+		LeftJoin("books b2 ON c1.book_id = b2.book_id").
+		LeftJoin("chapters c2 ON sn.related_entity_id = c2.chapter_id AND sn.related_entity_type = 'CHAPTER'").
+		LeftJoin("books b3 ON c2.book_id = b3.book_id").
+		LeftJoin("books b4 ON sn.related_entity_id = b4.book_id AND sn.related_entity_type = 'BOOK'").
+		Where(squirrel.Eq{"sn.user_id": userID}).
+		Where("NOT EXISTS (SELECT 1 FROM user_blocks ub WHERE ub.blocker_id = sn.user_id AND ub.blocked_id = sn.actor_id)")
+
+	builder, err := applyKeyset(builder, notificationKeyset, page)
 	if err != nil {
-		return nil, fmt.Errorf("gagal mengambil notifikasi: %w", err)
+		return nil, cursor.Page{}, err
 	}
-	return notifications, nil
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, cursor.Page{}, fmt.Errorf("gagal menyusun query notifikasi: %w", err)
+	}
+
+	var notifications []tables.NotificationResponse
+	if err := pgxscan.Select(ctx, d.DB, &notifications, query, args...); err != nil {
+		return nil, cursor.Page{}, fmt.Errorf("gagal mengambil notifikasi: %w", err)
+	}
+	notifications, cursors := finishKeysetPage(notifications, page, NotificationCursorKey, func(n tables.NotificationResponse) []string {
+		return []string{keysetTime(n.CreateDatetime), keysetInt(n.NotificationID)}
+	})
+	return notifications, cursors, nil
 }
 
 // CountNotificationsByUserID menghitung total notifikasi untuk seorang pengguna.
+// Notifikasi dari aktor yang diblokir atau dibisukan tidak dihitung, sama seperti GetNotificationsByUserID.
 func (d *NotificationDao) CountNotificationsByUserID(ctx context.Context, userID int64) (int64, error) {
 	var count int64
-	query := `SELECT COUNT(*) FROM system_notifications WHERE user_id = $1`
+	query := `
+		SELECT COUNT(*) FROM system_notifications sn
+		WHERE sn.user_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_blocks ub
+			WHERE ub.blocker_id = sn.user_id AND ub.blocked_id = sn.actor_id
+		  )`
 	err := d.DB.QueryRow(ctx, query, userID).Scan(&count)
 	return count, err
 }
\ No newline at end of file
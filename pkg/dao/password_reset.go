@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrResetTokenInvalid dikembalikan jika token reset tidak dikenal, sudah dipakai, atau kedaluwarsa.
+var ErrResetTokenInvalid = errors.New("token reset password tidak valid atau kedaluwarsa")
+
+// PasswordResetDao menangani operasi database untuk tabel password_reset_tokens.
+type PasswordResetDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewPasswordResetDao(db *pgxpool.Pool) *PasswordResetDao {
+	return &PasswordResetDao{DB: db}
+}
+
+// CreateResetToken menyimpan token reset baru dan membatalkan token lama milik pengguna
+// yang belum dipakai, sehingga hanya link terbaru yang berlaku.
+func (d *PasswordResetDao) CreateResetToken(ctx context.Context, userID int64, tokenHash string, expires time.Time) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE password_reset_tokens SET used_datetime = NOW()
+		WHERE user_id = $1 AND used_datetime IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("gagal membatalkan token lama: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_datetime)
+		VALUES ($1, $2, $3)`, userID, tokenHash, expires)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan token reset: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ResetPassword memakai token reset untuk mengganti password dalam satu transaksi:
+// token ditandai terpakai, password diganti, dan semua sesi pengguna dicabut.
+// Mengembalikan user_id pemilik token.
+func (d *PasswordResetDao) ResetPassword(ctx context.Context, tokenHash, newPasswordHash string) (int64, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_datetime = NOW()
+		WHERE token_hash = $1 AND used_datetime IS NULL AND expires_datetime > NOW()
+		RETURNING user_id`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrResetTokenInvalid
+		}
+		return 0, fmt.Errorf("gagal memakai token reset: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET password = $1 WHERE user_id = $2`, newPasswordHash, userID); err != nil {
+		return 0, fmt.Errorf("gagal mengganti password: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_sessions SET revoked_datetime = NOW()
+		WHERE user_id = $1 AND revoked_datetime IS NULL`, userID); err != nil {
+		return 0, fmt.Errorf("gagal mencabut sesi: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,150 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPayoutAccountUnchanged dikembalikan jika bank dan nomor rekening yang baru sama dengan yang lama.
+var ErrPayoutAccountUnchanged = errors.New("rekening pencairan tidak berubah")
+
+// PayoutAccountDao menangani rekening pencairan di tabel users beserta riwayatnya di payout_account_changes.
+type PayoutAccountDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewPayoutAccountDao(db *pgxpool.Pool) *PayoutAccountDao {
+	return &PayoutAccountDao{DB: db}
+}
+
+// GetPayoutAccount mengambil rekening pencairan pengguna beserta waktu mulai berlakunya,
+// atau nil jika pengguna belum punya rekening.
+func (d *PayoutAccountDao) GetPayoutAccount(ctx context.Context, userID int64) (*tables.PayoutAccount, error) {
+	var account tables.PayoutAccount
+	const query = `
+		SELECT
+			u.bank_id, b.bank_name, b.bank_code, u.account_number,
+			(
+				SELECT pac.effective_datetime FROM payout_account_changes pac
+				WHERE pac.user_id = u.user_id
+				ORDER BY pac.create_datetime DESC
+				LIMIT 1
+			) AS effective_datetime
+		FROM users u
+		JOIN banks b ON b.bank_id = u.bank_id
+		WHERE u.user_id = $1 AND u.deleted_datetime IS NULL
+		  AND u.account_number IS NOT NULL AND u.account_number <> ''`
+	if err := pgxscan.Get(ctx, d.DB, &account, query, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil rekening pencairan: %w", err)
+	}
+	account.IsOnHold = account.EffectiveDatetime != nil && account.EffectiveDatetime.After(time.Now())
+	return &account, nil
+}
+
+// ChangePayoutAccount mengganti rekening pencairan pengguna dan mencatatnya ke riwayat dalam satu transaksi.
+// Jika pengguna sudah punya rekening sebelumnya, rekening baru baru berlaku setelah coolingOff;
+// rekening pertama langsung berlaku. Field Old*, EffectiveDatetime, ChangeID, dan CreateDatetime
+// pada change diisi oleh method ini.
+func (d *PayoutAccountDao) ChangePayoutAccount(ctx context.Context, change *tables.PayoutAccountChange, coolingOff time.Duration) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris pengguna agar dua perubahan bersamaan tercatat berurutan
+	const lockQuery = `
+		SELECT u.bank_id, u.account_number, b.bank_name
+		FROM users u
+		LEFT JOIN banks b ON b.bank_id = u.bank_id
+		WHERE u.user_id = $1 AND u.deleted_datetime IS NULL
+		FOR UPDATE OF u`
+	err = tx.QueryRow(ctx, lockQuery, change.UserID).Scan(&change.OldBankID, &change.OldAccountNumber, &change.OldBankName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("gagal mengambil rekening lama: %w", err)
+	}
+	hasOldAccount := change.OldBankName != nil && change.OldAccountNumber != nil && *change.OldAccountNumber != ""
+	if !hasOldAccount {
+		// bank_id bawaan (-99) atau kosong dianggap belum punya rekening
+		change.OldBankID, change.OldAccountNumber, change.OldBankName = nil, nil, nil
+	} else if *change.OldBankID == change.NewBankID && *change.OldAccountNumber == change.NewAccountNumber {
+		return ErrPayoutAccountUnchanged
+	}
+
+	change.EffectiveDatetime = time.Now()
+	if hasOldAccount {
+		change.EffectiveDatetime = change.EffectiveDatetime.Add(coolingOff)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users SET bank_id = $1, account_number = $2, update_datetime = NOW()
+		WHERE user_id = $3`,
+		change.NewBankID, change.NewAccountNumber, change.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan rekening pencairan: %w", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO payout_account_changes (
+			user_id, old_bank_id, old_account_number, new_bank_id, new_account_number,
+			source, ip_address, user_agent, effective_datetime
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING change_id, create_datetime`
+	err = tx.QueryRow(ctx, insertQuery,
+		change.UserID, change.OldBankID, change.OldAccountNumber, change.NewBankID, change.NewAccountNumber,
+		change.Source, change.IPAddress, change.UserAgent, change.EffectiveDatetime,
+	).Scan(&change.ChangeID, &change.CreateDatetime)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat rekening pencairan: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
+
+// GetChanges mengambil riwayat perubahan rekening pencairan pengguna, yang terbaru lebih dulu.
+func (d *PayoutAccountDao) GetChanges(ctx context.Context, userID int64, limit, offset int) ([]tables.PayoutAccountChange, error) {
+	var changes []tables.PayoutAccountChange
+	const query = `
+		SELECT
+			pac.change_id, pac.user_id, pac.old_bank_id, ob.bank_name AS old_bank_name, pac.old_account_number,
+			pac.new_bank_id, nb.bank_name AS new_bank_name, pac.new_account_number,
+			pac.source, pac.ip_address, pac.user_agent, pac.effective_datetime, pac.create_datetime
+		FROM payout_account_changes pac
+		JOIN banks nb ON nb.bank_id = pac.new_bank_id
+		LEFT JOIN banks ob ON ob.bank_id = pac.old_bank_id
+		WHERE pac.user_id = $1
+		ORDER BY pac.create_datetime DESC, pac.change_id DESC
+		LIMIT $2 OFFSET $3`
+	if err := pgxscan.Select(ctx, d.DB, &changes, query, userID, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil riwayat rekening pencairan: %w", err)
+	}
+	return changes, nil
+}
+
+// CountChanges menghitung jumlah perubahan rekening pencairan pengguna.
+func (d *PayoutAccountDao) CountChanges(ctx context.Context, userID int64) (int64, error) {
+	var total int64
+	const query = `SELECT COUNT(*) FROM payout_account_changes WHERE user_id = $1`
+	if err := d.DB.QueryRow(ctx, query, userID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("gagal menghitung riwayat rekening pencairan: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,182 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPhoneVerificationNotFound dikembalikan jika OTP sudah dipakai, kedaluwarsa, atau sudah terlalu banyak dicoba.
+var ErrPhoneVerificationNotFound = errors.New("kode verifikasi tidak ditemukan atau sudah tidak berlaku")
+
+// PhoneSendStats adalah ringkasan pengiriman OTP dalam satu jendela waktu, dipakai untuk rate limit.
+type PhoneSendStats struct {
+	UserSends    int        `db:"user_sends"`     // Jumlah OTP yang dikirim untuk pengguna ini
+	PhoneSends   int        `db:"phone_sends"`    // Jumlah OTP yang dikirim ke nomor ini, dari akun mana pun
+	LastSentAt   *time.Time `db:"last_sent_at"`   // Waktu OTP terakhir untuk pengguna ini
+	OldestSentAt *time.Time `db:"oldest_sent_at"` // Waktu OTP tertua dalam jendela, untuk menghitung Retry-After
+}
+
+// PhoneVerificationDao menangani operasi database untuk tabel phone_verifications.
+type PhoneVerificationDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewPhoneVerificationDao(db *pgxpool.Pool) *PhoneVerificationDao {
+	return &PhoneVerificationDao{DB: db}
+}
+
+// sendStats menghitung pengiriman OTP sejak waktu tertentu, per pengguna dan per nomor tujuan.
+// Batas per nomor mencegah satu nomor dibanjiri OTP dari banyak akun.
+func sendStats(ctx context.Context, q pgxscan.Querier, userID int64, phone string, since time.Time) (*PhoneSendStats, error) {
+	var stats PhoneSendStats
+	const query = `
+		SELECT
+			COUNT(*) FILTER (WHERE user_id = $1) AS user_sends,
+			COUNT(*) FILTER (WHERE phone = $2) AS phone_sends,
+			MAX(create_datetime) FILTER (WHERE user_id = $1) AS last_sent_at,
+			MIN(create_datetime) AS oldest_sent_at
+		FROM phone_verifications
+		WHERE (user_id = $1 OR phone = $2) AND create_datetime > $3::timestamptz`
+	if err := pgxscan.Get(ctx, q, &stats, query, userID, phone, since); err != nil {
+		return nil, fmt.Errorf("gagal menghitung pengiriman OTP: %w", err)
+	}
+	return &stats, nil
+}
+
+// CreateVerification menyimpan OTP baru jika rate limit masih mengizinkan. Pengiriman sejak waktu
+// since dihitung di dalam transaksi yang sama, setelah mengunci pengguna dan nomor tujuan dengan
+// advisory lock, sehingga permintaan paralel tidak bisa melewati batas. Jika check mengembalikan
+// error, OTP tidak disimpan dan error tersebut dikembalikan apa adanya.
+// OTP lain milik pengguna yang masih berlaku langsung dibatalkan agar hanya kode terakhir yang bisa dipakai.
+func (d *PhoneVerificationDao) CreateVerification(ctx context.Context, verification *tables.PhoneVerification, since time.Time, check func(*PhoneSendStats) error) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Urutan kunci selalu pengguna lalu nomor agar dua transaksi tidak saling menunggu
+	for _, key := range []string{fmt.Sprintf("phone_otp:user:%d", verification.UserID), "phone_otp:phone:" + verification.Phone} {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+			return fmt.Errorf("gagal mengunci rate limit OTP: %w", err)
+		}
+	}
+	stats, err := sendStats(ctx, tx, verification.UserID, verification.Phone, since)
+	if err != nil {
+		return err
+	}
+	if err := check(stats); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE phone_verifications SET expires_datetime = NOW()
+		WHERE user_id = $1 AND verified_datetime IS NULL AND expires_datetime > NOW()`,
+		verification.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal membatalkan OTP lama: %w", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO phone_verifications (user_id, phone, channel, code_hash, expires_datetime)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING verification_id, create_datetime`
+	err = tx.QueryRow(ctx, insertQuery,
+		verification.UserID, verification.Phone, verification.Channel, verification.CodeHash, verification.ExpiresDatetime,
+	).Scan(&verification.VerificationID, &verification.CreateDatetime)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan OTP: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
+
+// GetPendingVerification mengambil OTP terakhir pengguna yang belum dipakai dan belum kedaluwarsa,
+// atau nil jika tidak ada.
+func (d *PhoneVerificationDao) GetPendingVerification(ctx context.Context, userID int64) (*tables.PhoneVerification, error) {
+	var verification tables.PhoneVerification
+	const query = `
+		SELECT verification_id, user_id, phone, channel, code_hash, attempts, expires_datetime, verified_datetime, create_datetime
+		FROM phone_verifications
+		WHERE user_id = $1 AND verified_datetime IS NULL AND expires_datetime > NOW()
+		ORDER BY create_datetime DESC
+		LIMIT 1`
+	err := pgxscan.Get(ctx, d.DB, &verification, query, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil OTP: %w", err)
+	}
+	return &verification, nil
+}
+
+// ConsumeAttempt mencatat satu percobaan untuk OTP secara atomik. Mengembalikan
+// ErrPhoneVerificationNotFound jika OTP sudah tidak berlaku atau batas percobaan tercapai,
+// sehingga permintaan paralel tidak bisa melewati batas.
+func (d *PhoneVerificationDao) ConsumeAttempt(ctx context.Context, verificationID int64, maxAttempts int) error {
+	const query = `
+		UPDATE phone_verifications SET attempts = attempts + 1
+		WHERE verification_id = $1 AND attempts < $2
+		  AND verified_datetime IS NULL AND expires_datetime > NOW()`
+	cmdTag, err := d.DB.Exec(ctx, query, verificationID, maxAttempts)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat percobaan OTP: %w", err)
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return ErrPhoneVerificationNotFound
+	}
+	return nil
+}
+
+// CompleteVerification menandai OTP sudah dipakai lalu menyimpan nomornya ke profil pengguna
+// sebagai nomor terverifikasi, dalam satu transaksi.
+func (d *PhoneVerificationDao) CompleteVerification(ctx context.Context, verificationID, userID int64, phone string) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE phone_verifications SET verified_datetime = NOW()
+		WHERE verification_id = $1 AND user_id = $2 AND verified_datetime IS NULL`,
+		verificationID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("gagal menandai OTP: %w", err)
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return ErrPhoneVerificationNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users SET phone = $1, is_phone_verified = TRUE, update_datetime = NOW()
+		WHERE user_id = $2`,
+		phone, userID,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_phone_key" {
+			return ErrPhoneTaken
+		}
+		return fmt.Errorf("gagal menyimpan nomor terverifikasi: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrRefreshTokenInvalid dikembalikan jika refresh token tidak dikenal, sudah dicabut, atau kedaluwarsa.
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid atau kedaluwarsa")
+	// ErrRefreshTokenReused dikembalikan jika refresh token lama dipakai ulang setelah dirotasi.
+	// Sesi terkait langsung dicabut karena token kemungkinan besar sudah dicuri.
+	ErrRefreshTokenReused = errors.New("refresh token sudah pernah dipakai, sesi dicabut")
+	// ErrSessionNotFound dikembalikan jika sesi tidak ditemukan, bukan milik pengguna, atau sudah dicabut.
+	ErrSessionNotFound = errors.New("sesi tidak ditemukan atau sudah dicabut")
+)
+
+// SessionDao menangani operasi database untuk tabel user_sessions.
+type SessionDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewSessionDao(db *pgxpool.Pool) *SessionDao {
+	return &SessionDao{DB: db}
+}
+
+// CreateSession membuat sesi baru dan mengembalikan session_id-nya.
+func (d *SessionDao) CreateSession(ctx context.Context, session *tables.UserSession) (int64, error) {
+	const query = `
+		INSERT INTO user_sessions (user_id, refresh_token_hash, device_name, user_agent, ip_address, expires_datetime, second_factor_datetime)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING session_id`
+
+	var sessionID int64
+	err := d.DB.QueryRow(ctx, query,
+		session.UserID,
+		session.RefreshTokenHash,
+		session.DeviceName,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresDatetime,
+		session.SecondFactorDatetime,
+	).Scan(&sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("gagal membuat sesi: %w", err)
+	}
+	return sessionID, nil
+}
+
+// RotateRefreshToken mengganti refresh token sebuah sesi dengan token baru dalam satu transaksi.
+// Jika token yang diberikan adalah token lama yang sudah dirotasi, sesi tersebut dicabut.
+func (d *SessionDao) RotateRefreshToken(ctx context.Context, oldHash, newHash string, newExpires time.Time, ipAddress string) (*tables.UserSession, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var session tables.UserSession
+	const selectQuery = `
+		SELECT
+			session_id, user_id, refresh_token_hash, previous_token_hash, device_name, user_agent, ip_address,
+			expires_datetime, revoked_datetime, last_seen_datetime, create_datetime, update_datetime
+		FROM user_sessions
+		WHERE refresh_token_hash = $1
+		FOR UPDATE`
+
+	err = pgxscan.Get(ctx, tx, &session, selectQuery, oldHash)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("gagal mengambil sesi: %w", err)
+		}
+
+		// Token tidak aktif, cek apakah ini token lama yang dipakai ulang.
+		cmdTag, err := tx.Exec(ctx, `
+			UPDATE user_sessions SET revoked_datetime = NOW()
+			WHERE previous_token_hash = $1 AND revoked_datetime IS NULL`, oldHash)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencabut sesi: %w", err)
+		}
+		if cmdTag.RowsAffected() > 0 {
+			if err := tx.Commit(ctx); err != nil {
+				return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+			}
+			logrus.Warn("Refresh token lama dipakai ulang, sesi terkait dicabut")
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	if session.RevokedDatetime != nil || time.Now().After(session.ExpiresDatetime) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	const updateQuery = `
+		UPDATE user_sessions SET
+			refresh_token_hash = $1,
+			previous_token_hash = $2,
+			expires_datetime = $3,
+			ip_address = $4,
+			last_seen_datetime = NOW()
+		WHERE session_id = $5`
+
+	if _, err := tx.Exec(ctx, updateQuery, newHash, oldHash, newExpires, ipAddress, session.SessionID); err != nil {
+		return nil, fmt.Errorf("gagal merotasi refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+
+	session.RefreshTokenHash = newHash
+	session.PreviousTokenHash = &oldHash
+	session.ExpiresDatetime = newExpires
+	session.IPAddress = ipAddress
+	return &session, nil
+}
+
+// TouchSession memeriksa apakah sesi milik pengguna masih aktif (belum dicabut dan belum kedaluwarsa),
+// sekaligus memperbarui last_seen_datetime dan ip_address. Penulisan hanya terjadi jika aktivitas
+// terakhir lebih lama dari touchInterval, sehingga request beruntun tidak menulis ke database.
+func (d *SessionDao) TouchSession(ctx context.Context, sessionID, userID int64, ipAddress string, touchInterval time.Duration) (bool, error) {
+	var active bool
+	const query = `
+		WITH active_session AS (
+			SELECT session_id, last_seen_datetime FROM user_sessions
+			WHERE session_id = $1 AND user_id = $2
+			  AND revoked_datetime IS NULL AND expires_datetime > NOW()
+		), touched AS (
+			UPDATE user_sessions s SET last_seen_datetime = NOW(), ip_address = $3
+			FROM active_session a
+			WHERE s.session_id = a.session_id
+			  AND a.last_seen_datetime < NOW() - make_interval(secs => $4::int)
+			RETURNING s.session_id
+		)
+		SELECT EXISTS (SELECT 1 FROM active_session)`
+	err := d.DB.QueryRow(ctx, query, sessionID, userID, ipAddress, int(touchInterval.Seconds())).Scan(&active)
+	return active, err
+}
+
+// GetActiveSessions mengambil semua sesi aktif milik pengguna, yang terakhir dipakai lebih dulu.
+func (d *SessionDao) GetActiveSessions(ctx context.Context, userID int64) ([]tables.ActiveSession, error) {
+	var sessions []tables.ActiveSession
+	const query = `
+		SELECT session_id, device_name, user_agent, ip_address, create_datetime, last_seen_datetime, expires_datetime
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_datetime IS NULL AND expires_datetime > NOW()
+		ORDER BY last_seen_datetime DESC`
+	if err := pgxscan.Select(ctx, d.DB, &sessions, query, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar sesi: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession mencabut satu sesi milik pengguna.
+func (d *SessionDao) RevokeSession(ctx context.Context, sessionID, userID int64) error {
+	const query = `
+		UPDATE user_sessions SET revoked_datetime = NOW()
+		WHERE session_id = $1 AND user_id = $2 AND revoked_datetime IS NULL`
+	cmdTag, err := d.DB.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllUserSessions mencabut semua sesi aktif milik pengguna dan mengembalikan jumlah sesi yang dicabut.
+func (d *SessionDao) RevokeAllUserSessions(ctx context.Context, userID int64) (int64, error) {
+	const query = `
+		UPDATE user_sessions SET revoked_datetime = NOW()
+		WHERE user_id = $1 AND revoked_datetime IS NULL`
+	cmdTag, err := d.DB.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// RevokeOtherUserSessions mencabut semua sesi aktif milik pengguna kecuali sesi yang sedang dipakai.
+func (d *SessionDao) RevokeOtherUserSessions(ctx context.Context, userID, currentSessionID int64) (int64, error) {
+	const query = `
+		UPDATE user_sessions SET revoked_datetime = NOW()
+		WHERE user_id = $1 AND session_id <> $2 AND revoked_datetime IS NULL`
+	cmdTag, err := d.DB.Exec(ctx, query, userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"noversystem/pkg/cursor"
 	"noversystem/pkg/tables"
 
 	"github.com/Masterminds/squirrel"
@@ -19,12 +20,20 @@ func NewTransactionDao(db *pgxpool.Pool) *TransactionDao {
 	return &TransactionDao{DB: db}
 }
 
-// GetTransactionsByUserID mengambil riwayat transaksi berdasarkan tipe (pendapatan/pengeluaran).
-func (d *TransactionDao) GetTransactionsByUserID(ctx context.Context, userID int64, isDebit bool, limit, offset int) ([]tables.CoinTransaction, error) {
-	var transactions []tables.CoinTransaction
+// transactionKeyset adalah urutan keyset riwayat transaksi, terbaru lebih dulu.
+var transactionKeyset = []keysetColumn{{"create_datetime", "timestamptz"}, {"transaction_id", "bigint"}}
 
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+// TransactionCursorKey adalah nama daftar transaksi di cursor. Riwayat pendapatan dan pengeluaran
+// memakai nama berbeda agar cursor satu tipe tidak dipakai untuk tipe lain.
+func TransactionCursorKey(isDebit bool) string {
+	if isDebit {
+		return "transactions:spend"
+	}
+	return "transactions:earn"
+}
 
+// transactionConditions adalah kondisi riwayat transaksi pengguna berdasarkan tipe (pendapatan/pengeluaran).
+func transactionConditions(userID int64, isDebit bool) squirrel.And {
 	conditions := squirrel.And{
 		squirrel.Eq{"user_id": userID},
 	}
@@ -33,6 +42,15 @@ func (d *TransactionDao) GetTransactionsByUserID(ctx context.Context, userID int
 	} else {
 		conditions = append(conditions, squirrel.Gt{"amount": 0})
 	}
+	return conditions
+}
+
+// GetTransactionsByUserID mengambil riwayat transaksi berdasarkan tipe (pendapatan/pengeluaran)
+// dengan pagination offset atau cursor.
+func (d *TransactionDao) GetTransactionsByUserID(ctx context.Context, userID int64, isDebit bool, page PageRequest) ([]tables.CoinTransaction, cursor.Page, error) {
+	var transactions []tables.CoinTransaction
+
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
 	// ✨ PERBAIKAN: Ganti SELECT * dengan kolom yang spesifik
 	queryBuilder := psql.Select(
@@ -45,21 +63,44 @@ func (d *TransactionDao) GetTransactionsByUserID(ctx context.Context, userID int
 		"expiry_date",
 		"create_datetime",
 	).From("coin_transactions").
-		Where(conditions).
-		OrderBy("create_datetime DESC").
-		Limit(uint64(limit)).
-		Offset(uint64(offset))
+		Where(transactionConditions(userID, isDebit))
+
+	queryBuilder, err := applyKeyset(queryBuilder, transactionKeyset, page)
+	if err != nil {
+		return nil, cursor.Page{}, err
+	}
 
 	sql, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("gagal membangun query transaksi: %w", err)
+		return nil, cursor.Page{}, fmt.Errorf("gagal membangun query transaksi: %w", err)
 	}
 
 	err = pgxscan.Select(ctx, d.DB, &transactions, sql, args...)
 	if err != nil {
 		// Tambahkan log ini untuk melihat error database yang lebih spesifik
 		logrus.WithError(err).Error("Error saat scanning data transaksi")
-		return nil, fmt.Errorf("gagal mengambil transaksi: %w", err)
+		return nil, cursor.Page{}, fmt.Errorf("gagal mengambil transaksi: %w", err)
+	}
+	transactions, cursors := finishKeysetPage(transactions, page, TransactionCursorKey(isDebit), func(t tables.CoinTransaction) []string {
+		return []string{keysetTime(t.CreateDatetime), keysetInt(t.TransactionID)}
+	})
+	return transactions, cursors, nil
+}
+
+// CountTransactionsByUserID menghitung total transaksi pengguna untuk tipe yang sama dengan GetTransactionsByUserID.
+func (d *TransactionDao) CountTransactionsByUserID(ctx context.Context, userID int64, isDebit bool) (int64, error) {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	sql, args, err := psql.Select("COUNT(*)").From("coin_transactions").
+		Where(transactionConditions(userID, isDebit)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("gagal membangun query hitung transaksi: %w", err)
+	}
+
+	var count int64
+	if err := d.DB.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("gagal menghitung transaksi: %w", err)
 	}
-	return transactions, nil
+	return count, nil
 }
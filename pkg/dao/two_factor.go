@@ -0,0 +1,219 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrTwoFactorAlreadyEnabled dikembalikan jika pengguna mendaftar ulang saat 2FA masih aktif.
+var ErrTwoFactorAlreadyEnabled = errors.New("verifikasi dua langkah sudah aktif")
+
+// TwoFactorDao menangani operasi database untuk tabel user_totp dan user_recovery_codes.
+type TwoFactorDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewTwoFactorDao(db *pgxpool.Pool) *TwoFactorDao {
+	return &TwoFactorDao{DB: db}
+}
+
+// GetTOTP mengambil data TOTP pengguna, atau nil jika pengguna belum pernah mendaftar.
+func (d *TwoFactorDao) GetTOTP(ctx context.Context, userID int64) (*tables.UserTOTP, error) {
+	var totp tables.UserTOTP
+	const query = `
+		SELECT user_id, secret, confirmed_datetime, last_used_step, create_datetime, update_datetime
+		FROM user_totp WHERE user_id = $1`
+	if err := pgxscan.Get(ctx, d.DB, &totp, query, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil data TOTP: %w", err)
+	}
+	return &totp, nil
+}
+
+// IsEnabled memeriksa apakah pengguna sudah mengaktifkan 2FA.
+func (d *TwoFactorDao) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	const query = `SELECT EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_datetime IS NOT NULL)`
+	err := d.DB.QueryRow(ctx, query, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// SaveEnrollment menyimpan secret baru yang belum dikonfirmasi, menggantikan pendaftaran
+// sebelumnya yang belum selesai. Mengembalikan ErrTwoFactorAlreadyEnabled jika 2FA sudah aktif.
+func (d *TwoFactorDao) SaveEnrollment(ctx context.Context, userID int64, secret string) error {
+	const query = `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, create_datetime = NOW()
+		WHERE user_totp.confirmed_datetime IS NULL`
+	cmdTag, err := d.DB.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan pendaftaran TOTP: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+	return nil
+}
+
+// Confirm mengaktifkan 2FA dalam satu transaksi: pendaftaran ditandai terkonfirmasi, kode pemulihan
+// disimpan, dan sesi yang dipakai langsung dianggap sudah lolos 2FA.
+func (d *TwoFactorDao) Confirm(ctx context.Context, userID, sessionID, step int64, recoveryCodeHashes []string) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE user_totp SET confirmed_datetime = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_datetime IS NULL`, userID, step)
+	if err != nil {
+		return fmt.Errorf("gagal mengonfirmasi TOTP: %w", err)
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodesTx(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+	if err := markSessionVerifiedTx(ctx, tx, sessionID, userID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
+
+// UseStep menandai langkah waktu TOTP sebagai terpakai. Mengembalikan false jika langkah tersebut
+// (atau yang lebih baru) sudah dipakai, misalnya saat kode yang sama dikirim dua kali bersamaan.
+func (d *TwoFactorDao) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	const query = `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND confirmed_datetime IS NOT NULL AND last_used_step < $2`
+	cmdTag, err := d.DB.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("gagal menandai kode TOTP: %w", err)
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode memakai satu kode pemulihan. Mengembalikan false jika kode tidak dikenal atau sudah dipakai.
+func (d *TwoFactorDao) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	const query = `
+		UPDATE user_recovery_codes SET used_datetime = NOW()
+		WHERE code_id = (
+			SELECT code_id FROM user_recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_datetime IS NULL
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)`
+	cmdTag, err := d.DB.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("gagal memakai kode pemulihan: %w", err)
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// CountRecoveryCodes menghitung kode pemulihan yang belum dipakai.
+func (d *TwoFactorDao) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	var count int
+	const query = `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_datetime IS NULL`
+	err := d.DB.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// ReplaceRecoveryCodes menghapus semua kode pemulihan lama dan menyimpan kode baru.
+func (d *TwoFactorDao) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodesTx(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Disable mematikan 2FA dan menghapus semua kode pemulihan.
+func (d *TwoFactorDao) Disable(ctx context.Context, userID int64) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menghapus kode pemulihan: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menghapus data TOTP: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkSessionVerified mencatat bahwa sesi baru saja lolos verifikasi 2FA.
+func (d *TwoFactorDao) MarkSessionVerified(ctx context.Context, sessionID, userID int64) error {
+	return markSessionVerifiedTx(ctx, d.DB, sessionID, userID)
+}
+
+// GetFreshness mengembalikan apakah 2FA aktif untuk pengguna, dan kapan sesi terakhir kali lolos 2FA.
+func (d *TwoFactorDao) GetFreshness(ctx context.Context, userID, sessionID int64) (bool, *time.Time, error) {
+	var (
+		enabled    bool
+		verifiedAt *time.Time
+	)
+	const query = `
+		SELECT
+			EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_datetime IS NOT NULL),
+			(SELECT second_factor_datetime FROM user_sessions WHERE session_id = $2 AND user_id = $1)`
+	if err := d.DB.QueryRow(ctx, query, userID, sessionID).Scan(&enabled, &verifiedAt); err != nil {
+		return false, nil, fmt.Errorf("gagal memeriksa status 2FA sesi: %w", err)
+	}
+	return enabled, verifiedAt, nil
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func replaceRecoveryCodesTx(ctx context.Context, tx pgx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("gagal menghapus kode pemulihan lama: %w", err)
+	}
+	for _, codeHash := range codeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, codeHash); err != nil {
+			return fmt.Errorf("gagal menyimpan kode pemulihan: %w", err)
+		}
+	}
+	return nil
+}
+
+func markSessionVerifiedTx(ctx context.Context, db execer, sessionID, userID int64) error {
+	const query = `
+		UPDATE user_sessions SET second_factor_datetime = NOW()
+		WHERE session_id = $1 AND user_id = $2 AND revoked_datetime IS NULL`
+	cmdTag, err := db.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("gagal menandai sesi: %w", err)
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return errors.New("sesi tidak ditemukan atau sudah dicabut")
+	}
+	return nil
+}
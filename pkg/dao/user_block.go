@@ -0,0 +1,103 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserBlockDao menangani operasi database untuk tabel user_blocks.
+type UserBlockDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewUserBlockDao(db *pgxpool.Pool) *UserBlockDao {
+	return &UserBlockDao{DB: db}
+}
+
+// Block memblokir pengguna. Relasi MUTE yang sudah ada dinaikkan menjadi BLOCK.
+// Mengembalikan ErrUserNotFound jika pengguna yang diblokir tidak ada.
+func (d *UserBlockDao) Block(ctx context.Context, blockerID, blockedID int64) error {
+	const query = `
+		INSERT INTO user_blocks (blocker_id, blocked_id, block_type)
+		VALUES ($1, $2, 'BLOCK')
+		ON CONFLICT (blocker_id, blocked_id) DO UPDATE SET block_type = 'BLOCK'
+		WHERE user_blocks.block_type <> 'BLOCK'`
+	return d.insertRelation(ctx, query, blockerID, blockedID)
+}
+
+// Mute membisukan pengguna. Jika pengguna sudah diblokir, relasi BLOCK dipertahankan karena
+// BLOCK sudah mencakup efek MUTE.
+func (d *UserBlockDao) Mute(ctx context.Context, blockerID, blockedID int64) error {
+	const query = `
+		INSERT INTO user_blocks (blocker_id, blocked_id, block_type)
+		VALUES ($1, $2, 'MUTE')
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+	return d.insertRelation(ctx, query, blockerID, blockedID)
+}
+
+func (d *UserBlockDao) insertRelation(ctx context.Context, query string, blockerID, blockedID int64) error {
+	if _, err := d.DB.Exec(ctx, query, blockerID, blockedID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("gagal menyimpan relasi blokir: %w", err)
+	}
+	return nil
+}
+
+// Remove menghapus relasi dengan tipe tertentu. Mengembalikan false jika relasi tersebut tidak ada,
+// misalnya saat membatalkan bisukan pada pengguna yang sebenarnya diblokir.
+func (d *UserBlockDao) Remove(ctx context.Context, blockerID, blockedID int64, blockType string) (bool, error) {
+	const query = `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2 AND block_type = $3::user_block_type`
+	cmdTag, err := d.DB.Exec(ctx, query, blockerID, blockedID, blockType)
+	if err != nil {
+		return false, fmt.Errorf("gagal menghapus relasi blokir: %w", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// IsBlocked memeriksa apakah blockerID memblokir (bukan sekadar membisukan) blockedID.
+func (d *UserBlockDao) IsBlocked(ctx context.Context, blockerID, blockedID int64) (bool, error) {
+	var blocked bool
+	const query = `SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2 AND block_type = 'BLOCK')`
+	err := d.DB.QueryRow(ctx, query, blockerID, blockedID).Scan(&blocked)
+	return blocked, err
+}
+
+// ListBlocked mengambil pengguna yang diblokir/dibisukan, yang terbaru lebih dulu.
+// blockType kosong berarti semua tipe.
+func (d *UserBlockDao) ListBlocked(ctx context.Context, blockerID int64, blockType string, limit, offset int) ([]tables.BlockedUser, error) {
+	var users []tables.BlockedUser
+	const query = `
+		SELECT
+			u.user_id,
+			COALESCE(u.pen_name, u.full_name) AS display_name,
+			u.avatar_url,
+			ub.block_type,
+			ub.create_datetime
+		FROM user_blocks ub
+		JOIN users u ON u.user_id = ub.blocked_id
+		WHERE ub.blocker_id = $1 AND ($2::text = '' OR ub.block_type::text = $2::text)
+		ORDER BY ub.create_datetime DESC
+		LIMIT $3 OFFSET $4`
+
+	if err := pgxscan.Select(ctx, d.DB, &users, query, blockerID, blockType, limit, offset); err != nil {
+		return nil, fmt.Errorf("gagal mengambil daftar pengguna yang diblokir: %w", err)
+	}
+	return users, nil
+}
+
+// CountBlocked menghitung pengguna yang diblokir/dibisukan. blockType kosong berarti semua tipe.
+func (d *UserBlockDao) CountBlocked(ctx context.Context, blockerID int64, blockType string) (int64, error) {
+	var count int64
+	const query = `SELECT COUNT(*) FROM user_blocks WHERE blocker_id = $1 AND ($2::text = '' OR block_type::text = $2::text)`
+	err := d.DB.QueryRow(ctx, query, blockerID, blockType).Scan(&count)
+	return count, err
+}
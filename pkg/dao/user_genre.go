@@ -0,0 +1,124 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrTooManyGenres dikembalikan jika jumlah genre favorit melebihi batas.
+var ErrTooManyGenres = errors.New("jumlah genre favorit melebihi batas")
+
+// UserGenreDao menangani operasi database untuk tabel user_genres (genre favorit pengguna).
+type UserGenreDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewUserGenreDao(db *pgxpool.Pool) *UserGenreDao {
+	return &UserGenreDao{DB: db}
+}
+
+// GetGenresByUserID mengambil genre favorit pengguna yang masih aktif, urut berdasarkan nama.
+func (d *UserGenreDao) GetGenresByUserID(ctx context.Context, userID int64) ([]tables.Genre, error) {
+	var genres []tables.Genre
+	const query = `
+		SELECT
+			g.genre_id, g.genre_name, g.genre_tl, g.remark, g.active_datetime,
+			g.non_active_datetime, g.create_datetime, g.update_datetime
+		FROM user_genres ug
+		JOIN genres g ON g.genre_id = ug.genre_id
+		WHERE ug.user_id = $1 AND g.non_active_datetime IS NULL
+		ORDER BY g.genre_name ASC`
+
+	if err := pgxscan.Select(ctx, d.DB, &genres, query, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil genre favorit: %w", err)
+	}
+	return genres, nil
+}
+
+// SetUserGenres mengganti seluruh genre favorit pengguna dengan daftar baru dalam satu transaksi.
+func (d *UserGenreDao) SetUserGenres(ctx context.Context, userID int64, genreIDs []int64) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if genreIDs == nil {
+		genreIDs = []int64{} // NULL akan membuat kondisi ANY tidak pernah bernilai true
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM user_genres WHERE user_id = $1 AND NOT (genre_id = ANY($2::bigint[]))`, userID, genreIDs)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus genre favorit lama: %w", err)
+	}
+	if err := insertUserGenresTx(ctx, tx, userID, genreIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
+
+// AddUserGenres menambahkan genre favorit tanpa menghapus yang sudah ada. Genre yang sudah
+// menjadi favorit diabaikan. Mengembalikan ErrTooManyGenres jika total favorit yang masih aktif melebihi maxGenres.
+func (d *UserGenreDao) AddUserGenres(ctx context.Context, userID int64, genreIDs []int64, maxGenres int) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertUserGenresTx(ctx, tx, userID, genreIDs); err != nil {
+		return err
+	}
+
+	// Genre yang sudah dinonaktifkan tidak ditampilkan sebagai favorit, jadi tidak ikut dihitung
+	var total int
+	const countQuery = `
+		SELECT COUNT(*) FROM user_genres ug
+		JOIN genres g ON g.genre_id = ug.genre_id
+		WHERE ug.user_id = $1 AND g.non_active_datetime IS NULL`
+	if err := tx.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+		return fmt.Errorf("gagal menghitung genre favorit: %w", err)
+	}
+	if total > maxGenres {
+		return ErrTooManyGenres
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return nil
+}
+
+// RemoveUserGenre menghapus satu genre favorit. Mengembalikan false jika genre tersebut memang bukan favorit.
+func (d *UserGenreDao) RemoveUserGenre(ctx context.Context, userID, genreID int64) (bool, error) {
+	cmdTag, err := d.DB.Exec(ctx, `DELETE FROM user_genres WHERE user_id = $1 AND genre_id = $2`, userID, genreID)
+	if err != nil {
+		return false, fmt.Errorf("gagal menghapus genre favorit: %w", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// insertUserGenresTx menyisipkan genre favorit di dalam transaksi. Duplikat diabaikan
+// berkat constraint user_loves_genre_once.
+func insertUserGenresTx(ctx context.Context, tx pgx.Tx, userID int64, genreIDs []int64) error {
+	if len(genreIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO user_genres (user_id, genre_id)
+		SELECT $1, UNNEST($2::bigint[])
+		ON CONFLICT ON CONSTRAINT user_loves_genre_once DO NOTHING`, userID, genreIDs)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan genre favorit: %w", err)
+	}
+	return nil
+}
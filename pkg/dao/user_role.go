@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"noversystem/pkg/tables"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrUserNotFound dikembalikan jika pengguna yang dituju tidak ada.
+var ErrUserNotFound = errors.New("pengguna tidak ditemukan")
+
+// UserRoleDao menangani operasi database untuk tabel user_roles.
+type UserRoleDao struct {
+	DB *pgxpool.Pool
+}
+
+func NewUserRoleDao(db *pgxpool.Pool) *UserRoleDao {
+	return &UserRoleDao{DB: db}
+}
+
+// GetRolesByUserID mengambil nama-nama role milik pengguna.
+func (d *UserRoleDao) GetRolesByUserID(ctx context.Context, userID int64) ([]string, error) {
+	roles := make([]string, 0, 2)
+	const query = `SELECT role::text FROM user_roles WHERE user_id = $1 ORDER BY role`
+	if err := pgxscan.Select(ctx, d.DB, &roles, query, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil role pengguna: %w", err)
+	}
+	return roles, nil
+}
+
+// ListUserRoles mengambil detail role milik pengguna, termasuk siapa yang memberikannya.
+func (d *UserRoleDao) ListUserRoles(ctx context.Context, userID int64) ([]tables.UserRole, error) {
+	var roles []tables.UserRole
+	const query = `
+		SELECT user_id, role::text AS role, granted_by, create_datetime
+		FROM user_roles
+		WHERE user_id = $1
+		ORDER BY role`
+	if err := pgxscan.Select(ctx, d.DB, &roles, query, userID); err != nil {
+		return nil, fmt.Errorf("gagal mengambil role pengguna: %w", err)
+	}
+	return roles, nil
+}
+
+// GrantRole memberikan role ke pengguna. Tidak melakukan apa-apa jika role sudah dimiliki.
+// Role author ikut menyalakan flg_author agar kode lama yang membaca flag tersebut tetap konsisten.
+func (d *UserRoleDao) GrantRole(ctx context.Context, userID int64, role string, grantedBy *int64) error {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := grantRoleTx(ctx, tx, userID, role, grantedBy); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if role == tables.RoleAuthor {
+		if _, err := tx.Exec(ctx, `UPDATE users SET flg_author = 'Y' WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("gagal memperbarui flg_author: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeRole mencabut role dari pengguna. Role author ikut mematikan flg_author.
+// Mengembalikan false jika pengguna memang tidak memiliki role tersebut.
+func (d *UserRoleDao) RevokeRole(ctx context.Context, userID int64, role string) (bool, error) {
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND role = $2`, userID, role)
+	if err != nil {
+		return false, fmt.Errorf("gagal mencabut role: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if role == tables.RoleAuthor {
+		if _, err := tx.Exec(ctx, `UPDATE users SET flg_author = 'N' WHERE user_id = $1`, userID); err != nil {
+			return false, fmt.Errorf("gagal memperbarui flg_author: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("gagal commit transaksi: %w", err)
+	}
+	return true, nil
+}
+
+// BootstrapAdmins memberikan role admin ke pengguna yang terdaftar di userIDs, hanya jika belum ada
+// admin sama sekali. Dengan begitu admin yang sudah dicabut tidak diberi role lagi setiap aplikasi
+// dijalankan ulang. ID yang tidak ada di tabel users dilewati. Mengembalikan jumlah role yang diberikan.
+func (d *UserRoleDao) BootstrapAdmins(ctx context.Context, userIDs []int64) (int64, error) {
+	const query = `
+		INSERT INTO user_roles (user_id, role)
+		SELECT u.user_id, $2::user_role FROM users u
+		WHERE u.user_id = ANY($1::bigint[])
+		  AND NOT EXISTS (SELECT 1 FROM user_roles WHERE role = $2::user_role)
+		ON CONFLICT (user_id, role) DO NOTHING`
+	cmdTag, err := d.DB.Exec(ctx, query, userIDs, tables.RoleAdmin)
+	if err != nil {
+		return 0, fmt.Errorf("gagal memberikan role admin awal: %w", err)
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// grantRoleTx menyisipkan role di dalam transaksi yang sedang berjalan.
+func grantRoleTx(ctx context.Context, tx pgx.Tx, userID int64, role string, grantedBy *int64) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, role) DO NOTHING`, userID, role, grantedBy)
+	if err != nil {
+		return fmt.Errorf("gagal memberikan role: %w", err)
+	}
+	return nil
+}
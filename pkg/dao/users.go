@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql" // PENTING: Import untuk menggunakan sql.NullString
 	"errors"
+	"fmt"
 	"noversystem/pkg/tables" // Pastikan path ini sesuai dengan struktur proyek Anda
+	"strings"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -46,9 +49,9 @@ func (d *UserDao) RegisterUser(ctx context.Context, user *tables.User) (int64, e
 		).
 		Values(
 			user.UserCode, user.Email, user.Password, user.FullName, user.Username, user.LoginWith,
-			"",    // avatar_url default
-			false, // is_email_verified default
-			"N",   // flg_author default
+			user.AvatarURL,       // kosong untuk registrasi lokal, foto profil untuk login Google
+			user.IsEmailVerified, // false untuk registrasi lokal, diambil dari Google untuk login sosial
+			"N",                  // flg_author default
 			// Memberikan nilai NULL jika tidak ada input
 			penName,
 			phone,
@@ -61,19 +64,35 @@ func (d *UserDao) RegisterUser(ctx context.Context, user *tables.User) (int64, e
 		return 0, err
 	}
 
+	tx, err := d.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	var newUserID int64
-	err = d.DB.QueryRow(ctx, sql, args...).Scan(&newUserID)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&newUserID)
 	if err != nil {
 		return 0, err
 	}
 
+	// Setiap pengguna baru otomatis mendapat role reader
+	if err := grantRoleTx(ctx, tx, newUserID, tables.RoleReader, nil); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
 	return newUserID, nil
 }
 
 // DIPERBAIKI: Menggunakan SELECT * untuk memastikan semua data pengguna terambil
+// Hanya akun yang memiliki password (akun lokal, atau akun lokal yang sudah ditautkan ke Google).
+// Pencocokan email tidak membedakan huruf besar/kecil.
 func (d *UserDao) FindUserByEmail(ctx context.Context, email string) (*tables.User, error) {
 	var user tables.User
-	const sql = "SELECT * FROM users WHERE email = $1 AND login_with = 'local'"
+	const sql = "SELECT * FROM users WHERE LOWER(email) = LOWER($1) AND password <> ''"
 	err := pgxscan.Get(ctx, d.DB, &user, sql, email)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -85,9 +104,11 @@ func (d *UserDao) FindUserByEmail(ctx context.Context, email string) (*tables.Us
 }
 
 // DIPERBAIKI: Menggunakan SELECT * agar konsisten dan lengkap
+// Hanya akun yang memiliki password (akun lokal, atau akun lokal yang sudah ditautkan ke Google).
+// Pencocokan username tidak membedakan huruf besar/kecil.
 func (d *UserDao) FindUserByUsername(ctx context.Context, username string) (*tables.User, error) {
 	var user tables.User
-	const sql = `SELECT * FROM users WHERE username = $1 AND login_with = 'local'`
+	const sql = `SELECT * FROM users WHERE LOWER(username) = LOWER($1) AND password <> ''`
 	err := pgxscan.Get(ctx, d.DB, &user, sql, username)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -98,63 +119,85 @@ func (d *UserDao) FindUserByUsername(ctx context.Context, username string) (*tab
 	return &user, nil
 }
 
-type AuthorUpdateRequest struct {
-	PenName       string
-	Phone         string
-	Instagram     string
-	BankId        int64
-	AccountNumber string
+// FindUserByIdentifier mencari akun yang memiliki password berdasarkan username atau email.
+// Identifier yang mengandung '@' diperlakukan sebagai email, selain itu sebagai username.
+func (d *UserDao) FindUserByIdentifier(ctx context.Context, identifier string) (*tables.User, error) {
+	if strings.Contains(identifier, "@") {
+		return d.FindUserByEmail(ctx, identifier)
+	}
+	return d.FindUserByUsername(ctx, identifier)
 }
 
-func (d *UserDao) IsPenNameTaken(ctx context.Context, penName string) (bool, error) {
-	const query = `SELECT 1 FROM users WHERE pen_name = $1;`
-	var exists int
-	err := d.DB.QueryRow(ctx, query, penName).Scan(&exists)
+// FindUserByUserCode mencari pengguna berdasarkan user_code dan metode login.
+// Untuk login Google, user_code berisi Google ID (claim sub).
+func (d *UserDao) FindUserByUserCode(ctx context.Context, userCode, loginWith string) (*tables.User, error) {
+	var user tables.User
+	const sql = `SELECT * FROM users WHERE user_code = $1 AND login_with = $2`
+	err := pgxscan.Get(ctx, d.DB, &user, sql, userCode, loginWith)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return false, nil
+			return nil, nil
 		}
-		return false, err
+		return nil, err
 	}
-	return true, nil
+	return &user, nil
+}
+
+// FindAccountByEmail mencari pengguna berdasarkan email tanpa memandang metode login.
+// Pencocokan email tidak membedakan huruf besar/kecil.
+func (d *UserDao) FindAccountByEmail(ctx context.Context, email string) (*tables.User, error) {
+	var user tables.User
+	const sql = `SELECT * FROM users WHERE LOWER(email) = LOWER($1)`
+	err := pgxscan.Get(ctx, d.DB, &user, sql, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (d *UserDao) UpdateUserToAuthor(ctx context.Context, userId int64, params AuthorUpdateRequest) error {
+// LinkGoogleAccount menautkan akun lokal ke akun Google dengan mengisi user_code dengan Google ID.
+// Password tetap disimpan sehingga pengguna masih bisa login dengan username dan password.
+func (d *UserDao) LinkGoogleAccount(ctx context.Context, userId int64, googleId string) error {
 	const query = `
 		UPDATE users SET
-			pen_name = $1,
-			phone = $2,
-			instagram = $3,
-			bank_id = $4,
-			account_number = $5,
-			flg_author = 'Y',
-			update_datetime = NOW()
-		WHERE user_id = $6;
+			user_code = $1,
+			login_with = 'google',
+			is_email_verified = TRUE
+		WHERE user_id = $2 AND login_with = 'local';
 	`
-	cmdTag, err := d.DB.Exec(ctx, query,
-		params.PenName,
-		params.Phone,
-		params.Instagram,
-		params.BankId,
-		params.AccountNumber,
-		userId,
-	)
+	cmdTag, err := d.DB.Exec(ctx, query, googleId, userId)
 	if err != nil {
 		return err
 	}
 	if cmdTag.RowsAffected() != 1 {
-		return errors.New("pengguna tidak ditemukan atau tidak ada data yang diperbarui")
+		return errors.New("akun lokal tidak ditemukan atau sudah ditautkan")
 	}
 	return nil
 }
 
+func (d *UserDao) IsPenNameTaken(ctx context.Context, penName string) (bool, error) {
+	const query = `SELECT 1 FROM users WHERE pen_name = $1;`
+	var exists int
+	err := d.DB.QueryRow(ctx, query, penName).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 func (d *UserDao) FindUserByID(ctx context.Context, userID int64) (*tables.User, error) {
 	var user tables.User
 	sql := `
         SELECT 
             user_id, user_code, email, password, full_name, username, pen_name,
-            avatar_url, login_with, is_email_verified, phone, instagram,
-            bank_id, account_number, flg_author, create_datetime, update_datetime
+            avatar_url, login_with, is_email_verified, phone, is_phone_verified, instagram,
+            bank_id, account_number, flg_author, bio, create_datetime, update_datetime
         FROM users 
         WHERE user_id = $1`
 
@@ -169,4 +212,197 @@ func (d *UserDao) FindUserByID(ctx context.Context, userID int64) (*tables.User,
 	}
 
 	return &user, nil
-}
\ No newline at end of file
+}
+
+// MarkEmailVerified menandai email pengguna sebagai terverifikasi.
+// Email ikut dicocokkan agar token verifikasi lama tidak berlaku setelah email diganti.
+func (d *UserDao) MarkEmailVerified(ctx context.Context, userID int64, email string) error {
+	const query = `UPDATE users SET is_email_verified = TRUE WHERE user_id = $1 AND LOWER(email) = LOWER($2)`
+	cmdTag, err := d.DB.Exec(ctx, query, userID, email)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return errors.New("pengguna tidak ditemukan atau email sudah berubah")
+	}
+	return nil
+}
+
+// UpdatePassword mengganti hash password pengguna.
+func (d *UserDao) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	const query = `UPDATE users SET password = $1 WHERE user_id = $2`
+	cmdTag, err := d.DB.Exec(ctx, query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return errors.New("pengguna tidak ditemukan")
+	}
+	return nil
+}
+
+var (
+	// ErrUsernameTaken, ErrPenNameTaken, dan ErrPhoneTaken dikembalikan UpdateProfile jika
+	// nilai yang diminta sudah dipakai pengguna lain (termasuk saat terjadi balapan request).
+	ErrUsernameTaken = errors.New("username sudah digunakan")
+	ErrPenNameTaken  = errors.New("nama pena sudah digunakan")
+	ErrPhoneTaken    = errors.New("nomor telepon sudah digunakan")
+)
+
+// ProfileUpdate berisi kolom profil yang boleh diubah sendiri oleh pengguna.
+// Field bernilai nil tidak diubah. Phone dan PenName berisi string kosong untuk menghapus nilainya.
+type ProfileUpdate struct {
+	FullName  *string
+	Username  *string
+	PenName   *string
+	AvatarURL *string
+	Instagram *string
+	Phone     *string
+	Bio       *string
+}
+
+// IsUsernameTakenByOther memeriksa apakah username sudah dipakai pengguna lain (tidak membedakan huruf besar/kecil).
+func (d *UserDao) IsUsernameTakenByOther(ctx context.Context, username string, userID int64) (bool, error) {
+	return d.isTakenByOther(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(username) = LOWER($1) AND user_id <> $2)`, username, userID)
+}
+
+// IsPenNameTakenByOther memeriksa apakah nama pena sudah dipakai pengguna lain.
+func (d *UserDao) IsPenNameTakenByOther(ctx context.Context, penName string, userID int64) (bool, error) {
+	return d.isTakenByOther(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE pen_name = $1 AND user_id <> $2)`, penName, userID)
+}
+
+// IsPhoneTakenByOther memeriksa apakah nomor telepon sudah dipakai pengguna lain.
+func (d *UserDao) IsPhoneTakenByOther(ctx context.Context, phone string, userID int64) (bool, error) {
+	return d.isTakenByOther(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE phone = $1 AND user_id <> $2)`, phone, userID)
+}
+
+func (d *UserDao) isTakenByOther(ctx context.Context, query, value string, userID int64) (bool, error) {
+	var taken bool
+	if err := d.DB.QueryRow(ctx, query, value, userID).Scan(&taken); err != nil {
+		return false, err
+	}
+	return taken, nil
+}
+
+// UpdateProfile memperbarui kolom profil yang diisi pada params.
+// Pelanggaran constraint UNIQUE diterjemahkan ke ErrUsernameTaken, ErrPenNameTaken, atau ErrPhoneTaken.
+func (d *UserDao) UpdateProfile(ctx context.Context, userID int64, params ProfileUpdate) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+
+	// Kolom UNIQUE yang dikosongkan disimpan sebagai NULL agar tidak bentrok dengan string kosong milik pengguna lain.
+	nullIfEmpty := func(v string) sql.NullString {
+		return sql.NullString{String: v, Valid: v != ""}
+	}
+
+	setMap := map[string]interface{}{}
+	if params.FullName != nil {
+		setMap["full_name"] = *params.FullName
+	}
+	if params.Username != nil {
+		setMap["username"] = *params.Username
+	}
+	if params.PenName != nil {
+		setMap["pen_name"] = nullIfEmpty(*params.PenName)
+	}
+	if params.AvatarURL != nil {
+		setMap["avatar_url"] = *params.AvatarURL
+	}
+	if params.Instagram != nil {
+		setMap["instagram"] = *params.Instagram
+	}
+	if params.Phone != nil {
+		setMap["phone"] = nullIfEmpty(*params.Phone)
+		// Status verifikasi hanya bertahan jika nomornya tidak berubah
+		setMap["is_phone_verified"] = squirrel.Expr("(is_phone_verified AND phone IS NOT DISTINCT FROM ?)", nullIfEmpty(*params.Phone))
+	}
+	if params.Bio != nil {
+		setMap["bio"] = nullIfEmpty(*params.Bio)
+	}
+	if len(setMap) == 0 {
+		return nil
+	}
+	setMap["update_datetime"] = squirrel.Expr("NOW()")
+
+	query, args, err := psql.Update("users").
+		SetMap(setMap).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	cmdTag, err := d.DB.Exec(ctx, query, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			switch pgErr.ConstraintName {
+			case "users_username_key", "users_username_lower_key":
+				return ErrUsernameTaken
+			case "users_pen_name_key":
+				return ErrPenNameTaken
+			case "users_phone_key":
+				return ErrPhoneTaken
+			}
+		}
+		return err
+	}
+	if cmdTag.RowsAffected() != 1 {
+		return errors.New("pengguna tidak ditemukan atau tidak ada data yang diperbarui")
+	}
+	return nil
+}
+
+// GetPublicAuthor mengambil data publik penulis sebuah buku, atau nil jika pengguna tidak ada.
+func (d *UserDao) GetPublicAuthor(ctx context.Context, authorID int64) (*tables.PublicAuthor, error) {
+	var author tables.PublicAuthor
+	const query = `
+		SELECT user_id AS author_id, pen_name, avatar_url, bio
+		FROM users
+		WHERE user_id = $1`
+
+	err := pgxscan.Get(ctx, d.DB, &author, query, authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil data penulis: %w", err)
+	}
+	return &author, nil
+}
+
+// GetAuthorProfile mengambil profil publik penulis beserta statistik dari buku yang sudah terbit (bukan draft).
+// Mengembalikan nil jika pengguna tidak ada atau bukan penulis.
+func (d *UserDao) GetAuthorProfile(ctx context.Context, authorID int64) (*tables.AuthorProfile, error) {
+	var profile tables.AuthorProfile
+	const query = `
+		WITH author_published_books AS (
+			SELECT b.book_id, b.total_views
+			FROM author_books ab
+			JOIN books b ON b.book_id = ab.book_id
+			WHERE ab.user_id = $1 AND b.status <> 'D'
+		)
+		SELECT
+			u.user_id AS author_id, u.pen_name, u.avatar_url, u.bio,
+			(SELECT COUNT(*) FROM author_published_books) AS total_books,
+			(SELECT COALESCE(SUM(total_views), 0)::bigint FROM author_published_books) AS total_views,
+			COALESCE(rv.average_rating, 0) AS average_rating,
+			COALESCE(rv.total_reviews, 0) AS total_reviews,
+			(SELECT COUNT(*) FROM author_follows af WHERE af.author_id = u.user_id) AS follower_count
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT ROUND(AVG(r.rating), 2)::float8 AS average_rating, COUNT(*) AS total_reviews
+			FROM reviews r
+			WHERE r.book_id IN (SELECT book_id FROM author_published_books)
+		) rv ON TRUE
+		WHERE u.user_id = $1
+			AND EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.user_id AND ur.role = 'author')`
+
+	err := pgxscan.Get(ctx, d.DB, &profile, query, authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gagal mengambil profil penulis: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,201 @@
+package googleauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSURL adalah endpoint resmi Google yang memuat public key untuk ID token.
+const DefaultJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// ErrKeyNotFound dikembalikan jika kid pada token tidak ada di JWKS.
+var ErrKeyNotFound = errors.New("public key untuk kid tersebut tidak ditemukan")
+
+// KeySource menyediakan public key RSA berdasarkan kid.
+// Implementasinya bisa mengambil JWKS dari Google atau dari file lokal (untuk pengujian).
+type KeySource interface {
+	PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// NewKeySource memilih sumber JWKS: file lokal jika jwksFile diisi, selain itu URL remote.
+func NewKeySource(jwksURL, jwksFile string) KeySource {
+	if jwksFile != "" {
+		return NewFileKeySource(jwksFile)
+	}
+	if jwksURL == "" {
+		jwksURL = DefaultJWKSURL
+	}
+	return NewRemoteKeySource(jwksURL)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// parseJWKS mengubah dokumen JWKS menjadi map kid -> public key RSA.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("gagal membaca JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus JWKS tidak valid untuk kid %s: %w", k.Kid, err)
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent JWKS tidak valid untuk kid %s: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(nBytes),
+			E: int(new(big.Int).SetBytes(eBytes).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// FileKeySource membaca JWKS dari file lokal. Cocok untuk development dan pengujian
+// dengan ID token yang ditandatangani oleh key milik sendiri.
+type FileKeySource struct {
+	path string
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func NewFileKeySource(path string) *FileKeySource {
+	return &FileKeySource{path: path}
+}
+
+func (s *FileKeySource) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil {
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca file JWKS: %w", err)
+		}
+		keys, err := parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = keys
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// RemoteKeySource mengambil JWKS dari URL dan menyimpannya di cache sesuai header Cache-Control.
+type RemoteKeySource struct {
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	expiresAt   time.Time
+	lastFetchAt time.Time
+}
+
+func NewRemoteKeySource(url string) *RemoteKeySource {
+	return &RemoteKeySource{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *RemoteKeySource) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key, ok := s.keys[kid]
+	// Ambil ulang jika cache habis, atau jika kid belum dikenal (Google baru saja merotasi key).
+	// Pengambilan karena kid tidak dikenal dibatasi sekali per menit.
+	if now.After(s.expiresAt) || (!ok && now.Sub(s.lastFetchAt) > time.Minute) {
+		if err := s.fetch(ctx); err != nil {
+			if ok {
+				return key, nil // Tetap pakai cache lama jika Google sedang tidak bisa dihubungi
+			}
+			return nil, err
+		}
+		key, ok = s.keys[kid]
+	}
+
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *RemoteKeySource) fetch(ctx context.Context) error {
+	s.lastFetchAt = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gagal mengambil JWKS: status %d", resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("gagal membaca JWKS: %w", err)
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.expiresAt = time.Now().Add(cacheMaxAge(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+// cacheMaxAge membaca nilai max-age dari header Cache-Control, default 1 jam.
+func cacheMaxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.TrimSpace(directive)
+		if value, found := strings.CutPrefix(directive, "max-age="); found {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return time.Hour
+}
@@ -0,0 +1,91 @@
+package googleauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNotConfigured dikembalikan jika belum ada client ID Google yang dikonfigurasi.
+	ErrNotConfigured = errors.New("login Google belum dikonfigurasi")
+	// ErrInvalidToken dikembalikan untuk semua ID token yang gagal diverifikasi.
+	ErrInvalidToken = errors.New("ID token Google tidak valid")
+)
+
+var validIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+// Claims adalah isi ID token Google yang dipakai oleh aplikasi.
+type Claims struct {
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	Picture       string       `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// Verifier memverifikasi ID token Google terhadap JWKS dan daftar client ID aplikasi.
+type Verifier struct {
+	keys      KeySource
+	clientIDs []string
+}
+
+func NewVerifier(keys KeySource, clientIDs []string) *Verifier {
+	return &Verifier{keys: keys, clientIDs: clientIDs}
+}
+
+// Verify memeriksa tanda tangan, issuer, audience, dan masa berlaku ID token.
+func (v *Verifier) Verify(ctx context.Context, idToken string) (*Claims, error) {
+	if len(v.clientIDs) == 0 {
+		return nil, ErrNotConfigured
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("header kid tidak ditemukan")
+		}
+		return v.keys.PublicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !slices.Contains(validIssuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: issuer %q tidak dikenal", ErrInvalidToken, claims.Issuer)
+	}
+	if !slices.ContainsFunc(claims.Audience, func(aud string) bool { return slices.Contains(v.clientIDs, aud) }) {
+		return nil, fmt.Errorf("%w: audience tidak cocok", ErrInvalidToken)
+	}
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, fmt.Errorf("%w: claim sub atau email kosong", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+// flexibleBool menerima email_verified dalam bentuk boolean maupun string "true"/"false".
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = flexibleBool(value)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*b = str == "true"
+	return nil
+}
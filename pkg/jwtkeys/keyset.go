@@ -0,0 +1,182 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claim dan header yang membedakan access token dari token lain yang ditandatangani key yang sama.
+// Layanan lain yang memverifikasi token lewat JWKS wajib memeriksa iss, aud, dan header typ,
+// bukan hanya tanda tangannya.
+const (
+	Issuer              = "nover"
+	AccessTokenAudience = "nover-api"
+	AccessTokenType     = "at+jwt" // RFC 9068
+	// purposeAudiencePrefix dipakai token sekali pakai (verifikasi email, tantangan 2FA) agar tidak
+	// pernah lolos sebagai access token.
+	purposeAudiencePrefix = "nover-internal:"
+)
+
+// PurposeAudience mengembalikan claim aud untuk token sekali pakai dengan keperluan tertentu.
+func PurposeAudience(purpose string) string {
+	return purposeAudiencePrefix + purpose
+}
+
+var (
+	// ErrNoKeys dikembalikan jika tidak ada key asimetris maupun secret lama yang dikonfigurasi.
+	ErrNoKeys = errors.New("JWT_KEYS atau JWT_SECRET_KEY harus diatur")
+	// ErrUnknownKey dikembalikan jika kid pada token tidak dikenal.
+	ErrUnknownKey = errors.New("kid token tidak dikenal")
+)
+
+// Config berisi pengaturan untuk memuat KeySet.
+type Config struct {
+	// KeyFiles adalah pasangan kid -> path file PEM. File boleh berisi private key (bisa dipakai
+	// untuk menandatangani) atau hanya public key (key lama yang masih diterima saat rotasi).
+	KeyFiles map[string]string
+	// SigningKeyID memilih key untuk menandatangani token baru. Wajib diisi jika ada lebih
+	// dari satu private key.
+	SigningKeyID string
+	// LegacySecret adalah secret HS256 lama. Token HS256 tanpa kid tetap diterima selama secret
+	// ini diisi, dan dipakai untuk menandatangani jika belum ada key asimetris.
+	LegacySecret string
+}
+
+// Key adalah satu key penandatangan/verifikasi beserta algoritmanya.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+// KeySet menyimpan key aktif untuk menandatangani dan semua key yang masih diterima untuk verifikasi.
+type KeySet struct {
+	signing      *Key
+	keys         map[string]*Key
+	legacySecret []byte
+}
+
+// Load membaca semua file key dan memilih key penandatangan.
+func Load(cfg Config) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(cfg.KeyFiles))}
+	if cfg.LegacySecret != "" {
+		set.legacySecret = []byte(cfg.LegacySecret)
+	}
+
+	var signingCandidates []string
+	for kid, path := range cfg.KeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca key %s: %w", kid, err)
+		}
+		key, err := parseKey(kid, data)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[kid] = key
+		if key.private != nil {
+			signingCandidates = append(signingCandidates, kid)
+		}
+	}
+
+	switch {
+	case cfg.SigningKeyID != "":
+		key, ok := set.keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("key penandatangan %s tidak ada di JWT_KEYS", cfg.SigningKeyID)
+		}
+		if key.private == nil {
+			return nil, fmt.Errorf("key penandatangan %s hanya berisi public key", cfg.SigningKeyID)
+		}
+		set.signing = key
+	case len(signingCandidates) == 1:
+		set.signing = set.keys[signingCandidates[0]]
+	case len(signingCandidates) > 1:
+		return nil, errors.New("ada lebih dari satu private key, atur JWT_SIGNING_KID")
+	case set.legacySecret == nil:
+		return nil, ErrNoKeys
+	}
+
+	return set, nil
+}
+
+// Sign menandatangani claims dengan key aktif dan menyertakan header kid. typ mengisi header typ
+// (misalnya AccessTokenType); kosong berarti "JWT". Jika belum ada key asimetris, token
+// ditandatangani dengan HS256 memakai secret lama.
+func (s *KeySet) Sign(claims jwt.Claims, typ string) (string, error) {
+	if s.signing == nil {
+		return withType(jwt.NewWithClaims(jwt.SigningMethodHS256, claims), typ).SignedString(s.legacySecret)
+	}
+
+	token := withType(jwt.NewWithClaims(s.signing.Method, claims), typ)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+func withType(token *jwt.Token, typ string) *jwt.Token {
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+	return token
+}
+
+// Parse memverifikasi token terhadap key yang sesuai dengan header kid-nya.
+// Token tanpa kid hanya diterima sebagai HS256 jika secret lama masih diatur.
+func (s *KeySet) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append(opts, jwt.WithValidMethods(s.validMethods()))
+	return jwt.ParseWithClaims(tokenString, claims, s.keyfunc, opts...)
+}
+
+func (s *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if s.legacySecret == nil || token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrUnknownKey
+		}
+		return s.legacySecret, nil
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	// Cegah serangan algorithm confusion: algoritma token harus sama dengan algoritma key
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("algoritma %s tidak cocok untuk kid %s", token.Method.Alg(), kid)
+	}
+	return key.Public, nil
+}
+
+func (s *KeySet) validMethods() []string {
+	methods := make([]string, 0, 3)
+	seen := make(map[string]bool, 3)
+	for _, key := range s.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	if s.legacySecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
+}
+
+// methodFor memilih algoritma JWT berdasarkan jenis public key.
+func methodFor(kid string, public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("jenis key %s tidak didukung, gunakan RSA atau Ed25519", kid)
+	}
+}
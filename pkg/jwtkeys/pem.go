@@ -0,0 +1,69 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// parseKey membaca private atau public key dari dokumen PEM.
+// Format yang didukung: PKCS#8 "PRIVATE KEY", PKCS#1 "RSA PRIVATE KEY", dan PKIX "PUBLIC KEY".
+func parseKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s bukan file PEM yang valid", kid)
+	}
+
+	var (
+		private crypto.PrivateKey
+		public  crypto.PublicKey
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca private key %s: %w", kid, err)
+		}
+		private = parsed
+		public = publicOf(parsed)
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca private key %s: %w", kid, err)
+		}
+		private = parsed
+		public = &parsed.PublicKey
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca public key %s: %w", kid, err)
+		}
+		public = parsed
+	default:
+		return nil, fmt.Errorf("jenis PEM %q untuk key %s tidak didukung", block.Type, kid)
+	}
+
+	method, err := methodFor(kid, public)
+	if err != nil {
+		return nil, err
+	}
+	if rsaKey, ok := public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("key RSA %s minimal 2048 bit", kid)
+	}
+
+	return &Key{ID: kid, Method: method, Public: public, private: private}, nil
+}
+
+func publicOf(private crypto.PrivateKey) crypto.PublicKey {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	default:
+		return nil
+	}
+}
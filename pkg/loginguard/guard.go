@@ -0,0 +1,169 @@
+package loginguard
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// State adalah catatan kegagalan login untuk satu key (akun atau alamat IP).
+type State struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+}
+
+// Store menyimpan state kegagalan login. Implementasinya bisa di memori (satu instance)
+// atau di Postgres (dibagi antar instance).
+type Store interface {
+	// Get mengembalikan state key, atau State kosong jika belum ada.
+	Get(ctx context.Context, key string) (State, error)
+	// RecordFailure menambah jumlah kegagalan secara atomik. Jika kegagalan terakhir lebih lama
+	// dari window, hitungan dimulai lagi dari 1. Mengembalikan state setelah ditambah.
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (State, error)
+	// Block menahan key sampai waktu tertentu.
+	Block(ctx context.Context, key string, until time.Time) error
+	// Reset menghapus state key, dipanggil setelah login berhasil.
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy mengatur kapan percobaan login ditahan.
+type Policy struct {
+	MaxFailures     int           // Jumlah kegagalan sebelum key dikunci
+	LockoutDuration time.Duration // Lama penguncian setelah MaxFailures tercapai
+	BaseDelay       time.Duration // Jeda setelah kegagalan pertama, berlipat dua setiap kegagalan berikutnya
+	MaxDelay        time.Duration // Batas atas jeda backoff
+	Window          time.Duration // Kegagalan yang lebih lama dari ini tidak dihitung lagi
+}
+
+// Decision adalah hasil pemeriksaan sebelum password dicocokkan.
+type Decision struct {
+	Allowed    bool
+	Locked     bool          // true jika ditolak karena penguncian, false jika karena backoff
+	RetryAfter time.Duration // Waktu tunggu sebelum boleh mencoba lagi
+}
+
+// Guard menerapkan backoff eksponensial dan penguncian sementara per akun dan per IP.
+type Guard struct {
+	store   Store
+	account Policy
+	ip      Policy
+	timeNow func() time.Time
+}
+
+// NewGuard membuat Guard dengan kebijakan terpisah untuk akun dan IP. Batas IP biasanya
+// lebih longgar karena banyak pengguna bisa berbagi satu IP (NAT kantor/kampus).
+func NewGuard(store Store, account, ip Policy) *Guard {
+	return &Guard{store: store, account: account, ip: ip, timeNow: time.Now}
+}
+
+// UserKey membuat key akun untuk pengguna yang terdaftar. Key berdasarkan user ID agar login lewat
+// username dan email berbagi satu hitungan kegagalan.
+func UserKey(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10)
+}
+
+// AccountKey membuat key untuk identifier login (username atau email) yang tidak cocok dengan akun
+// mana pun, tanpa membedakan huruf besar/kecil.
+func AccountKey(identifier string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// IPKey membuat key untuk alamat IP.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check memeriksa apakah akun dan IP boleh mencoba login sekarang. accountKey dibuat dengan UserKey
+// atau AccountKey.
+func (g *Guard) Check(ctx context.Context, accountKey, ip string) (Decision, error) {
+	now := g.timeNow()
+	decision := Decision{Allowed: true}
+
+	for _, key := range []string{accountKey, IPKey(ip)} {
+		state, err := g.store.Get(ctx, key)
+		if err != nil {
+			return Decision{Allowed: true}, err
+		}
+		if wait := state.BlockedUntil.Sub(now); wait > 0 && wait > decision.RetryAfter {
+			decision.Allowed = false
+			decision.RetryAfter = wait
+			decision.Locked = state.Failures >= g.policyFor(key).MaxFailures
+		}
+	}
+	return decision, nil
+}
+
+// Fail mencatat login gagal untuk akun dan IP, lalu menerapkan backoff atau penguncian.
+// Mengembalikan keputusan untuk percobaan berikutnya.
+func (g *Guard) Fail(ctx context.Context, accountKey, ip string) (Decision, error) {
+	now := g.timeNow()
+	decision := Decision{Allowed: true}
+
+	for _, key := range []string{accountKey, IPKey(ip)} {
+		policy := g.policyFor(key)
+		state, err := g.store.RecordFailure(ctx, key, now, policy.Window)
+		if err != nil {
+			return decision, err
+		}
+
+		locked, wait := policy.penalty(state.Failures)
+		if wait <= 0 {
+			continue
+		}
+		if err := g.store.Block(ctx, key, now.Add(wait)); err != nil {
+			return decision, err
+		}
+		if wait > decision.RetryAfter {
+			decision = Decision{Allowed: false, Locked: locked, RetryAfter: wait}
+		}
+	}
+	return decision, nil
+}
+
+// Succeed menghapus catatan kegagalan akun setelah login berhasil. Catatan IP sengaja
+// tidak dihapus agar satu akun valid tidak bisa dipakai untuk mereset hitungan IP.
+func (g *Guard) Succeed(ctx context.Context, accountKey string) error {
+	return g.store.Reset(ctx, accountKey)
+}
+
+func (g *Guard) policyFor(key string) Policy {
+	if strings.HasPrefix(key, "ip:") {
+		return g.ip
+	}
+	return g.account
+}
+
+// penalty menghitung lama penahanan setelah kegagalan ke-n: kunci penuh jika MaxFailures
+// tercapai, selain itu BaseDelay * 2^(n-1) dibatasi MaxDelay.
+func (p Policy) penalty(failures int) (locked bool, wait time.Duration) {
+	if failures <= 0 {
+		return false, 0
+	}
+	if p.MaxFailures > 0 && failures >= p.MaxFailures {
+		return true, p.LockoutDuration
+	}
+	if p.BaseDelay <= 0 {
+		return false, 0
+	}
+
+	wait = p.BaseDelay
+	for i := 1; i < failures && wait < p.MaxDelay; i++ {
+		wait *= 2
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	return false, wait
+}
+
+// NewStore membuat Store sesuai driver yang dipilih. Driver selain "postgres" memakai MemoryStore.
+func NewStore(driver string, db *pgxpool.Pool) Store {
+	if driver == "postgres" {
+		return NewPostgresStore(db)
+	}
+	return NewMemoryStore()
+}
@@ -0,0 +1,69 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan state di memori proses. Cocok untuk development atau deployment
+// satu instance; state hilang saat aplikasi restart.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, now time.Time, window time.Duration) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[key]
+	if window > 0 && now.Sub(state.LastFailure) > window && !now.Before(state.BlockedUntil) {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailure = now
+	s.states[key] = state
+	s.prune(now, window)
+	return state, nil
+}
+
+func (s *MemoryStore) Block(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[key]
+	state.BlockedUntil = until
+	s.states[key] = state
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// prune membuang state yang sudah kedaluwarsa agar map tidak tumbuh tanpa batas
+// ketika diserang dengan banyak username atau IP berbeda.
+func (s *MemoryStore) prune(now time.Time, window time.Duration) {
+	if window <= 0 || len(s.states) < 1024 {
+		return
+	}
+	for key, state := range s.states {
+		if now.Sub(state.LastFailure) > window && !now.Before(state.BlockedUntil) {
+			delete(s.states, key)
+		}
+	}
+}
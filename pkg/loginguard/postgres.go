@@ -0,0 +1,90 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore menyimpan state di tabel login_throttle sehingga batasan berlaku
+// untuk semua instance aplikasi.
+type PostgresStore struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (State, error) {
+	var (
+		state        State
+		blockedUntil *time.Time
+	)
+	const query = `
+		SELECT failures, last_failure_datetime, blocked_until_datetime
+		FROM login_throttle
+		WHERE throttle_key = $1`
+
+	err := s.DB.QueryRow(ctx, query, key).Scan(&state.Failures, &state.LastFailure, &blockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return State{}, nil
+		}
+		return State{}, fmt.Errorf("gagal mengambil data throttle login: %w", err)
+	}
+	if blockedUntil != nil {
+		state.BlockedUntil = *blockedUntil
+	}
+	return state, nil
+}
+
+// RecordFailure menambah hitungan dalam satu statement upsert agar aman dari race antar instance.
+func (s *PostgresStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (State, error) {
+	var (
+		state        State
+		blockedUntil *time.Time
+	)
+	const query = `
+		INSERT INTO login_throttle (throttle_key, failures, last_failure_datetime)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (throttle_key) DO UPDATE SET
+			failures = CASE
+				WHEN $3::bigint > 0
+					AND login_throttle.last_failure_datetime < $2::timestamptz - make_interval(secs => $3::bigint)
+					AND COALESCE(login_throttle.blocked_until_datetime, $2::timestamptz) <= $2::timestamptz
+				THEN 1
+				ELSE login_throttle.failures + 1
+			END,
+			last_failure_datetime = $2
+		RETURNING failures, last_failure_datetime, blocked_until_datetime`
+
+	err := s.DB.QueryRow(ctx, query, key, now, int64(window/time.Second)).Scan(&state.Failures, &state.LastFailure, &blockedUntil)
+	if err != nil {
+		return State{}, fmt.Errorf("gagal mencatat kegagalan login: %w", err)
+	}
+	if blockedUntil != nil {
+		state.BlockedUntil = *blockedUntil
+	}
+	return state, nil
+}
+
+func (s *PostgresStore) Block(ctx context.Context, key string, until time.Time) error {
+	_, err := s.DB.Exec(ctx, `UPDATE login_throttle SET blocked_until_datetime = $1 WHERE throttle_key = $2`, until, key)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan waktu blokir login: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	_, err := s.DB.Exec(ctx, `DELETE FROM login_throttle WHERE throttle_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("gagal mereset throttle login: %w", err)
+	}
+	return nil
+}
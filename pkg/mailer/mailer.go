@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message adalah email sederhana berformat teks.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah antarmuka pengirim email. Implementasi bisa diganti tanpa mengubah controller.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config berisi pengaturan untuk membuat Mailer.
+type Config struct {
+	Driver       string // "smtp" atau "log"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	LogDir       string // Jika diisi, LogMailer juga menyimpan setiap email sebagai file .eml
+}
+
+// New membuat Mailer sesuai driver yang dipilih (tidak membedakan huruf besar/kecil). Driver yang
+// tidak dikenal ditolak agar salah ketik tidak diam-diam membuat link reset password dan token
+// verifikasi hanya ditulis ke log.
+func New(cfg Config) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Driver)) {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "log":
+		return NewLogMailer(cfg.From, cfg.LogDir), nil
+	default:
+		return nil, fmt.Errorf("mail driver %q tidak dikenal", cfg.Driver)
+	}
+}
+
+// buildMessage menyusun email lengkap dengan header yang dibutuhkan.
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+// SMTPMailer mengirim email melalui server SMTP dengan otentikasi PLAIN.
+type SMTPMailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		host: host,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("gagal mengirim email ke %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// LogMailer tidak mengirim email sungguhan, hanya menulisnya ke log (dan ke file jika dir diisi).
+// Dipakai untuk development lokal.
+type LogMailer struct {
+	from string
+	dir  string
+}
+
+func NewLogMailer(from, dir string) *LogMailer {
+	return &LogMailer{from: from, dir: dir}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	logrus.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Info("Email (log mailer):\n" + msg.Body)
+
+	if m.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("gagal membuat direktori email: %w", err)
+	}
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("gagal menyimpan email: %w", err)
+	}
+	return nil
+}
@@ -1,63 +1,111 @@
 package middleware
 
 import (
-	"log"
-	"os"
+	"noversystem/pkg/config"
+	"noversystem/pkg/dao"
+	"noversystem/pkg/jwtkeys"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/joho/godotenv"
 )
 
-var JWTSecret []byte
-
-func init() {
-	_ = godotenv.Load()
-
-	secret := os.Getenv("JWT_SECRET_KEY")
-	if secret == "" {
-		log.Fatal("Variabel lingkungan JWT_SECRET_KEY tidak diatur. Aplikasi tidak dapat berjalan.")
+// Protected memvalidasi access token dan memastikan sesi yang dibawa token (claim sid)
+// belum dicabut, sehingga token yang sudah logout tidak bisa dipakai lagi.
+func Protected(keys *jwtkeys.KeySet, sessionDAO *dao.SessionDao) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Header otentikasi tidak ditemukan"})
+		}
+		if status, message := authenticate(c, keys, sessionDAO); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": message})
+		}
+		return c.Next()
 	}
-	JWTSecret = []byte(secret)
 }
 
-func Protected() fiber.Handler {
+// OptionalAuth dipakai di endpoint publik. Jika request membawa bearer token, token divalidasi
+// seperti Protected dan data pengguna diisi ke Locals. Tanpa header Authorization, request
+// diteruskan sebagai tamu. Token yang dikirim tetapi tidak valid tetap ditolak agar klien tahu
+// harus memperbarui token, bukan diam-diam diperlakukan sebagai tamu.
+func OptionalAuth(keys *jwtkeys.KeySet, sessionDAO *dao.SessionDao) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Header otentikasi tidak ditemukan"})
+		if c.Get("Authorization") == "" {
+			return c.Next()
 		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Format token tidak valid"})
+		if status, message := authenticate(c, keys, sessionDAO); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": message})
 		}
-		tokenString := parts[1]
+		return c.Next()
+	}
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "Metode signing tidak diharapkan")
-			}
-			return JWTSecret, nil
-		})
+// authenticate mem-parsing bearer token dari header Authorization, memeriksa sesinya,
+// lalu mengisi Locals userId, sessionId, dan roles. Mengembalikan status HTTP dan pesan
+// error jika gagal, atau status 0 jika berhasil.
+func authenticate(c *fiber.Ctx, keys *jwtkeys.KeySet, sessionDAO *dao.SessionDao) (int, string) {
+	parts := strings.Split(c.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return fiber.StatusUnauthorized, "Format token tidak valid"
+	}
+	tokenString := parts[1]
 
-		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token tidak valid atau kedaluwarsa"})
-		}
+	// Algoritma dan key dipilih berdasarkan header kid, bukan dari header alg yang dikirim klien.
+	// iss, aud, dan typ memastikan token sekali pakai (verifikasi email, tantangan 2FA) yang
+	// ditandatangani key yang sama tidak diterima sebagai access token.
+	token, err := keys.Parse(tokenString, jwt.MapClaims{},
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(jwtkeys.Issuer),
+		jwt.WithAudience(jwtkeys.AccessTokenAudience),
+	)
+	if err != nil {
+		return fiber.StatusUnauthorized, "Token tidak valid atau kedaluwarsa"
+	}
+	if typ, _ := token.Header["typ"].(string); typ != jwtkeys.AccessTokenType {
+		return fiber.StatusUnauthorized, "Jenis token tidak valid"
+	}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// --- PERUBAHAN UTAMA DI SINI ---
-			// Ubah "user_id" menjadi "userId" agar cocok dengan isi token
-			userIdFloat, ok := claims["userId"].(float64)
-			if !ok {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Claim userId tidak valid dalam token"})
-			}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return fiber.StatusUnauthorized, "Claim token tidak valid"
+	}
 
-			c.Locals("userId", int64(userIdFloat)) // Nama di Locals boleh tetap snake_case
-			return c.Next()
-		}
+	// Ubah "user_id" menjadi "userId" agar cocok dengan isi token
+	userIdFloat, ok := claims["userId"].(float64)
+	if !ok {
+		return fiber.StatusUnauthorized, "Claim userId tidak valid dalam token"
+	}
+	userId := int64(userIdFloat)
+
+	// Token tanpa sid berasal dari sistem lama dan tidak bisa dicabut, jadi ditolak.
+	sessionIdFloat, ok := claims["sid"].(float64)
+	if !ok {
+		return fiber.StatusUnauthorized, "Claim sid tidak valid dalam token"
+	}
+	sessionId := int64(sessionIdFloat)
 
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Claim token tidak valid"})
+	active, err := sessionDAO.TouchSession(c.Context(), sessionId, userId, c.IP(), config.Cfg.Auth.SessionTouchInterval)
+	if err != nil {
+		return fiber.StatusInternalServerError, "Gagal memeriksa sesi"
+	}
+	if !active {
+		return fiber.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali"
+	}
+
+	c.Locals("userId", userId) // Nama di Locals boleh tetap snake_case
+	c.Locals("sessionId", sessionId)
+	c.Locals("roles", rolesFromClaims(claims))
+	return 0, ""
+}
+
+// rolesFromClaims membaca claim roles dari token. Token lama tanpa claim roles dianggap tidak memiliki role.
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	rawRoles, _ := claims["roles"].([]interface{})
+	roles := make([]string, 0, len(rawRoles))
+	for _, rawRole := range rawRoles {
+		if role, ok := rawRole.(string); ok {
+			roles = append(roles, role)
+		}
 	}
+	return roles
 }
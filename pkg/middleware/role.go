@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"noversystem/pkg/constants"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole hanya meneruskan request dari pengguna yang memiliki salah satu role yang diberikan.
+// Role dibaca dari claim roles di access token, sehingga harus dipasang setelah Protected.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("userId").(int64); !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Pengguna tidak terotentikasi"})
+		}
+
+		for _, role := range roles {
+			if HasRole(c, role) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"code":    constants.ErrCodeUserForbidden,
+			"message": "You do not have permission to access this resource.",
+		})
+	}
+}
+
+// HasRole memeriksa apakah pengguna yang sedang login memiliki role tertentu.
+func HasRole(c *fiber.Ctx, role string) bool {
+	userRoles, _ := c.Locals("roles").([]string)
+	for _, userRole := range userRoles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
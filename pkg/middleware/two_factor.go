@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireFresh2FA menolak request dari pengguna yang memakai 2FA tetapi sesinya belum
+// diverifikasi ulang dalam TWO_FACTOR_FRESH_MINUTES terakhir. Klien diminta memanggil
+// /auth/2fa/verify lalu mengulang request. Pengguna tanpa 2FA tidak terpengaruh.
+// Harus dipasang setelah Protected.
+func RequireFresh2FA(twoFactorDAO *dao.TwoFactorDao) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, okUser := c.Locals("userId").(int64)
+		sessionId, okSession := c.Locals("sessionId").(int64)
+		if !okUser || !okSession || userId == 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Pengguna tidak terotentikasi"})
+		}
+
+		enabled, verifiedAt, err := twoFactorDAO.GetFreshness(c.Context(), userId, sessionId)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memeriksa status 2FA"})
+		}
+		if enabled && (verifiedAt == nil || time.Since(*verifiedAt) > config.Cfg.TwoFactor.FreshWindow) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"code":    constants.ErrCodeAuthTwoFactorRequired,
+				"message": "Please confirm with your two-factor code before continuing.",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"noversystem/pkg/config"
+	"noversystem/pkg/constants"
+	"noversystem/pkg/dao"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireVerifiedEmail menolak request dari pengguna yang emailnya belum terverifikasi.
+// Hanya aktif jika REQUIRE_VERIFIED_EMAIL=true. Harus dipasang setelah Protected.
+func RequireVerifiedEmail(userDAO *dao.UserDao) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !config.Cfg.Auth.RequireVerifiedEmail {
+			return c.Next()
+		}
+
+		userId, ok := c.Locals("userId").(int64)
+		if !ok || userId == 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Pengguna tidak terotentikasi"})
+		}
+
+		user, err := userDAO.FindUserByID(c.Context(), userId)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memeriksa status email"})
+		}
+		if user == nil || !user.IsEmailVerified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"code":    constants.ErrCodeAuthEmailNotVerified,
+				"message": "Please verify your email address before continuing.",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -1,13 +1,21 @@
 package routes
 
 import (
+	"noversystem/pkg/config"
 	"noversystem/pkg/controllers"
 	"noversystem/pkg/dao"
+	"noversystem/pkg/googleauth"
+	"noversystem/pkg/jwtkeys"
+	"noversystem/pkg/loginguard"
+	"noversystem/pkg/mailer"
+	"noversystem/pkg/sms"
 	"noversystem/pkg/middleware"
+	"noversystem/pkg/tables"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
 )
 
 func SetupRoutes(app *fiber.App, db *pgxpool.Pool) {
@@ -26,12 +34,89 @@ func SetupRoutes(app *fiber.App, db *pgxpool.Pool) {
 	transactionDAO := dao.NewTransactionDao(db) // ✨ 1. Inisialisasi TransactionDAO
 	checkinDAO := dao.NewCheckinDao(db)    // ✨ Inisialisasi DAO baru
 	missionDAO := dao.NewMissionDao(db)    // ✨ Inisialisasi DAO baru
+	sessionDAO := dao.NewSessionDao(db)
+	passwordResetDAO := dao.NewPasswordResetDao(db)
+	authorApplicationDAO := dao.NewAuthorApplicationDao(db)
+	userRoleDAO := dao.NewUserRoleDao(db)
+	loginAttemptDAO := dao.NewLoginAttemptDao(db)
+	userGenreDAO := dao.NewUserGenreDao(db)
+	authorFollowDAO := dao.NewAuthorFollowDao(db)
+	accountDAO := dao.NewAccountDao(db)
+	userBlockDAO := dao.NewUserBlockDao(db)
+	phoneVerificationDAO := dao.NewPhoneVerificationDao(db)
+	bankDAO := dao.NewBankDao(db)
+	payoutAccountDAO := dao.NewPayoutAccountDao(db)
+	twoFactorDAO := dao.NewTwoFactorDao(db)
 
 	// --- Auth Routes ---
-	authController := controllers.NewAuthController(userDAO)
+	jwtKeys, err := jwtkeys.Load(jwtkeys.Config{
+		KeyFiles:     config.Cfg.JWT.KeyFiles,
+		SigningKeyID: config.Cfg.JWT.SigningKeyID,
+		LegacySecret: config.Cfg.JWT.LegacySecret,
+	})
+	if err != nil {
+		logrus.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	protected := middleware.Protected(jwtKeys, sessionDAO)
+	optionalAuth := middleware.OptionalAuth(jwtKeys, sessionDAO)
+
+	googleVerifier := googleauth.NewVerifier(
+		googleauth.NewKeySource(config.Cfg.Google.JWKSURL, config.Cfg.Google.JWKSFile),
+		config.Cfg.Google.ClientIDs,
+	)
+	mail, err := mailer.New(mailer.Config{
+		Driver:       config.Cfg.Mail.Driver,
+		From:         config.Cfg.Mail.From,
+		SMTPHost:     config.Cfg.Mail.SMTPHost,
+		SMTPPort:     config.Cfg.Mail.SMTPPort,
+		SMTPUsername: config.Cfg.Mail.SMTPUsername,
+		SMTPPassword: config.Cfg.Mail.SMTPPassword,
+		LogDir:       config.Cfg.Mail.LogDir,
+	})
+	if err != nil {
+		logrus.Fatalf("Failed to set up mailer: %v", err)
+	}
+	loginGuard := loginguard.NewGuard(
+		loginguard.NewStore(config.Cfg.LoginGuard.Store, db),
+		loginguard.Policy{
+			MaxFailures:     config.Cfg.LoginGuard.MaxFailures,
+			LockoutDuration: config.Cfg.LoginGuard.LockoutDuration,
+			BaseDelay:       config.Cfg.LoginGuard.BackoffBase,
+			MaxDelay:        config.Cfg.LoginGuard.BackoffMax,
+			Window:          config.Cfg.LoginGuard.FailureWindow,
+		},
+		// IP hanya dikunci setelah banyak kegagalan tanpa backoff per percobaan,
+		// karena satu IP bisa dipakai bersama oleh banyak pengguna
+		loginguard.Policy{
+			MaxFailures:     config.Cfg.LoginGuard.IPMaxFailures,
+			LockoutDuration: config.Cfg.LoginGuard.LockoutDuration,
+			Window:          config.Cfg.LoginGuard.FailureWindow,
+		},
+	)
+	authController := controllers.NewAuthController(userDAO, sessionDAO, passwordResetDAO, userRoleDAO, loginAttemptDAO, twoFactorDAO, loginGuard, jwtKeys, googleVerifier, mail)
+	app.Get("/.well-known/jwks.json", authController.JWKS)
 	authGroup := api.Group("/auth")
 	authGroup.Post("/register", authController.Register)
 	authGroup.Post("/login", authController.Login)
+	authGroup.Post("/login/2fa", authController.VerifyLoginTwoFactor)
+	authGroup.Post("/google", authController.GoogleLogin)
+	authGroup.Post("/refresh", authController.RefreshToken)
+	authGroup.Post("/logout", protected, authController.Logout)
+	authGroup.Post("/logout-all", protected, authController.LogoutAll)
+	authGroup.Get("/sessions", protected, authController.GetMySessions)
+	authGroup.Post("/sessions/revoke-others", protected, authController.RevokeOtherSessions)
+	authGroup.Delete("/sessions/:sessionId<int>", protected, authController.RevokeMySession)
+	authGroup.Post("/email/verification", protected, authController.RequestEmailVerification)
+	authGroup.Post("/email/verify", authController.VerifyEmail)
+	authGroup.Post("/forgot-password", authController.ForgotPassword)
+	authGroup.Post("/reset-password", authController.ResetPassword)
+	authGroup.Post("/change-password", protected, authController.ChangePassword)
+	authGroup.Get("/2fa", protected, authController.GetTwoFactorStatus)
+	authGroup.Post("/2fa/enroll", protected, authController.EnrollTwoFactor)
+	authGroup.Post("/2fa/confirm", protected, authController.ConfirmTwoFactor)
+	authGroup.Post("/2fa/verify", protected, authController.VerifyTwoFactor)
+	authGroup.Post("/2fa/recovery-codes", protected, authController.RegenerateRecoveryCodes)
+	authGroup.Post("/2fa/disable", protected, authController.DisableTwoFactor)
 
 	// --- API v1 Group ---
 	apiV1 := api.Group("/v1")
@@ -46,53 +131,114 @@ func SetupRoutes(app *fiber.App, db *pgxpool.Pool) {
 	bankGroup.Get("/get", bankController.GetBankList)
 
 	// --- User Routes (Protected) ---
-	userController := controllers.NewUserController(userDAO)
+	userController := controllers.NewUserController(userDAO, authorApplicationDAO, bankDAO)
 	userGroup := apiV1.Group("/user")
-	protectedUserGroup := userGroup.Group("/", middleware.Protected())
-	protectedUserGroup.Post("/request-author", userController.RequestBecomeAuthor)
+	protectedUserGroup := userGroup.Group("/", protected)
+	protectedUserGroup.Post("/request-author", middleware.RequireVerifiedEmail(userDAO), middleware.RequireFresh2FA(twoFactorDAO), userController.RequestBecomeAuthor)
 	protectedUserGroup.Get("/author-status", userController.CheckAuthorStatus)
+	protectedUserGroup.Get("/me", userController.GetMyProfile)
+	protectedUserGroup.Patch("/me", userController.UpdateMyProfile)
+
+	accountController := controllers.NewAccountController(accountDAO, userDAO)
+	protectedUserGroup.Delete("/me", accountController.DeleteMyAccount)
+	protectedUserGroup.Get("/me/export", accountController.ExportMyData)
+
+	userGenreController := controllers.NewUserGenreController(genreDAO, userGenreDAO, bookDAO)
+	protectedUserGroup.Get("/me/genres", userGenreController.GetMyGenres)
+	protectedUserGroup.Put("/me/genres", userGenreController.SetMyGenres)
+	protectedUserGroup.Post("/me/genres", userGenreController.AddMyGenres)
+	protectedUserGroup.Delete("/me/genres/:genreId", userGenreController.RemoveMyGenre)
+
+	smsProvider, err := sms.New(sms.Config{Driver: config.Cfg.Phone.SMSDriver})
+	if err != nil {
+		logrus.Fatalf("Failed to set up SMS provider: %v", err)
+	}
+	phoneVerificationController := controllers.NewPhoneVerificationController(phoneVerificationDAO, userDAO, smsProvider)
+	protectedUserGroup.Post("/me/phone/otp", phoneVerificationController.SendPhoneOTP)
+	protectedUserGroup.Post("/me/phone/verify", phoneVerificationController.VerifyPhoneOTP)
+
+	// Mengganti rekening pencairan butuh verifikasi 2FA yang masih baru bagi pengguna yang memakai 2FA
+	payoutAccountController := controllers.NewPayoutAccountController(payoutAccountDAO, bankDAO, userDAO, mail)
+	protectedUserGroup.Get("/me/payout-account", middleware.RequireRole(tables.RoleAuthor), payoutAccountController.GetMyPayoutAccount)
+	protectedUserGroup.Put("/me/payout-account", middleware.RequireRole(tables.RoleAuthor), middleware.RequireVerifiedEmail(userDAO), middleware.RequireFresh2FA(twoFactorDAO), payoutAccountController.UpdateMyPayoutAccount)
+	protectedUserGroup.Get("/me/payout-account/history", middleware.RequireRole(tables.RoleAuthor), payoutAccountController.GetMyPayoutAccountHistory)
+
+	authorFollowController := controllers.NewAuthorFollowController(authorFollowDAO)
+	protectedUserGroup.Get("/me/following", authorFollowController.GetMyFollowing)
+
+	userBlockController := controllers.NewUserBlockController(userBlockDAO)
+	protectedUserGroup.Get("/me/blocks", userBlockController.GetMyBlockedUsers)
+	apiV1.Post("/users/:userId<int>/block", protected, userBlockController.BlockUser)
+	apiV1.Delete("/users/:userId<int>/block", protected, userBlockController.UnblockUser)
+	apiV1.Post("/users/:userId<int>/mute", protected, userBlockController.MuteUser)
+	apiV1.Delete("/users/:userId<int>/mute", protected, userBlockController.UnmuteUser)
+
+	// --- Admin Routes (Protected, khusus role admin) ---
+	authorApplicationController := controllers.NewAuthorApplicationController(authorApplicationDAO)
+	userRoleController := controllers.NewUserRoleController(userRoleDAO)
+	adminGroup := apiV1.Group("/admin", protected, middleware.RequireRole(tables.RoleAdmin))
+	adminGroup.Get("/author-applications", authorApplicationController.ListApplications)
+	adminGroup.Post("/author-applications/:applicationId/decision", authorApplicationController.DecideApplication)
+	adminGroup.Get("/users/:userId/roles", userRoleController.GetUserRoles)
+	adminGroup.Post("/users/:userId/roles", userRoleController.GrantRole)
+	adminGroup.Delete("/users/:userId/roles/:role", userRoleController.RevokeRole)
 
 	// --- Book Routes ---
-	bookController := controllers.NewBookController(bookDAO, userDAO, chapterDAO, reviewDAO)
-    bookCommentController := controllers.NewBookCommentController(bookCommentDAO, bookDAO, userDAO)
+	bookController := controllers.NewBookController(bookDAO, userDAO, chapterDAO, reviewDAO, authorFollowDAO, genreDAO)
+    bookCommentController := controllers.NewBookCommentController(bookCommentDAO, bookDAO, userDAO, userBlockDAO)
     notificationController := controllers.NewNotificationController(notificationDAO) // ✨ Inisialisasi Controller baru
     walletController := controllers.NewWalletController(walletDAO) // ✨ 2. Inisialisasi WalletController
 	transactionController := controllers.NewTransactionController(transactionDAO) // ✨ 3. Inisialisasi TransactionController
 	checkinController := controllers.NewCheckinController(checkinDAO) // ✨ Inisialisasi Controller baru
 	missionController := controllers.NewMissionController(missionDAO) // ✨ Inisialisasi Controller baru
 
-	// 👉 PUBLIC Book Endpoints (tidak pakai middleware, bebas akses tanpa token)
-	apiV1.Get("/books", bookController.GetPublishedBookList)
-	apiV1.Get("/authors/:authorId/books", bookController.GetBooksByAuthor)
-	apiV1.Get("/chapters/:chapterId", controllers.NewChapterController(chapterDAO, bookDAO).GetChapterContent)
-
-	apiV1.Get("/books/:bookId/comments", bookCommentController.GetBookComments)
+	// 👉 PUBLIC Book Endpoints (bebas akses tanpa token, token opsional untuk personalisasi)
+	// Harus didaftarkan sebelum bookGroup, karena middleware Protected milik grup /books
+	// berlaku untuk semua route /books/* yang didaftarkan setelahnya.
+	chapterController := controllers.NewChapterController(chapterDAO, bookDAO)
+	apiV1.Get("/books", optionalAuth, bookController.GetPublishedBookList)
+	apiV1.Get("/books/search", optionalAuth, bookController.SearchBooks)
+	authorController := controllers.NewAuthorController(userDAO, authorFollowDAO)
+	apiV1.Get("/authors/:authorId<int>", optionalAuth, authorController.GetAuthorProfile)
+	apiV1.Get("/authors/:authorId/books", optionalAuth, bookController.GetBooksByAuthor)
+	apiV1.Get("/authors/:authorId/followers", authorFollowController.GetAuthorFollowers)
+	apiV1.Post("/authors/:authorId/follow", protected, authorFollowController.FollowAuthor)
+	apiV1.Delete("/authors/:authorId/follow", protected, authorFollowController.UnfollowAuthor)
+	apiV1.Get("/chapters/:chapterId", optionalAuth, chapterController.GetChapterContent)
+
+	apiV1.Get("/books/:bookId<int>/comments", optionalAuth, bookCommentController.GetBookComments)
+	apiV1.Get("/books/:bookId<int>", optionalAuth, bookController.GetPublicBookDetail)
 
 	// 👉 PROTECTED Book Endpoints (wajib pakai token)
-	bookGroup := apiV1.Group("/books", middleware.Protected())
+	bookGroup := apiV1.Group("/books", protected)
 	bookGroup.Post("/create", bookController.CreateBook)
 	bookGroup.Get("/my-books", bookController.GetMyBooks)
+	bookGroup.Get("/recommended", userGenreController.GetRecommendedBooks)
 	bookGroup.Patch("/:bookId/publish", bookController.PublishBook)
 	bookGroup.Patch("/:bookId/unpublish", bookController.UnpublishBook)
 	bookGroup.Patch("/:bookId/complete", bookController.CompleteBook)
 	bookGroup.Patch("/:bookId/hold", bookController.HoldBook)
 	bookGroup.Get("/:bookId/detail", bookController.GetMyBookDetail)
+	bookGroup.Patch("/:bookId<int>", bookController.UpdateBook)
+	bookGroup.Get("/:bookId<int>/revisions", bookController.GetBookRevisions)
+	bookGroup.Get("/:bookId<int>/status-history", bookController.GetBookStatusHistory)
     bookGroup.Post("/:bookId/comments", bookCommentController.CreateBookComment)
 
 	// Chapter creation (Protected, karena di bawah bookGroup)
-	chapterController := controllers.NewChapterController(chapterDAO, bookDAO)
 	bookGroup.Post("/:bookId/chapters", chapterController.CreateChapter)
-	apiV1.Get("/books/:bookId", bookController.GetPublicBookDetail)
+	bookGroup.Patch("/:bookId<int>/chapters/:chapterId<int>/publish", chapterController.PublishChapter)
 
-	notifGroup := apiV1.Group("/notifications", middleware.Protected())
+	notifGroup := apiV1.Group("/notifications", protected)
     notifGroup.Get("/", notificationController.GetNotifications) // ✨ Daftarkan Route GET baru
 
-	walletGroup := apiV1.Group("/wallet", middleware.Protected())
+	// Route wallet saat ini hanya membaca saldo dan riwayat. Route yang memakai atau menghasilkan koin
+	// (misalnya check-in, dan nanti unlock chapter) wajib memasang RequireVerifiedEmail.
+	walletGroup := apiV1.Group("/wallet", protected)
     walletGroup.Get("/my-balance", walletController.GetMyWallet)
     walletGroup.Get("/transactions", transactionController.GetMyTransactions)
 
-	eventGroup := apiV1.Group("/events", middleware.Protected())
+	eventGroup := apiV1.Group("/events", protected)
 	eventGroup.Get("/check-in/status", checkinController.GetStatus)
-	eventGroup.Post("/check-in", checkinController.CheckIn)
+	eventGroup.Post("/check-in", middleware.RequireVerifiedEmail(userDAO), checkinController.CheckIn)
 	eventGroup.Get("/missions/daily", missionController.GetDailyMissions)
 }
@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Channel adalah jalur pengiriman pesan singkat.
+type Channel string
+
+const (
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+// Message adalah pesan teks singkat ke satu nomor telepon.
+type Message struct {
+	To      string // Nomor tujuan, hanya angka dengan awalan '+' opsional
+	Body    string
+	Channel Channel
+}
+
+// Provider adalah antarmuka pengirim SMS/WhatsApp. Implementasi untuk gateway tertentu
+// (misalnya Twilio atau penyedia WhatsApp Business) cukup memenuhi antarmuka ini
+// tanpa mengubah controller.
+type Provider interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config berisi pengaturan untuk membuat Provider.
+type Config struct {
+	Driver string // "console"
+}
+
+// New membuat Provider sesuai driver yang dipilih. Driver yang tidak dikenal ditolak agar salah
+// konfigurasi tidak diam-diam membuat OTP hanya ditulis ke log.
+func New(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", "console", "log":
+		return NewConsoleProvider(), nil
+	default:
+		return nil, fmt.Errorf("SMS driver %q tidak dikenal", cfg.Driver)
+	}
+}
+
+// ConsoleProvider tidak mengirim pesan sungguhan, hanya menulisnya ke log.
+// Dipakai untuk development lokal.
+type ConsoleProvider struct{}
+
+func NewConsoleProvider() *ConsoleProvider {
+	return &ConsoleProvider{}
+}
+
+func (p *ConsoleProvider) Send(ctx context.Context, msg Message) error {
+	logrus.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"channel": msg.Channel,
+	}).Info("Pesan (console SMS provider):\n" + msg.Body)
+	return nil
+}
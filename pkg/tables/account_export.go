@@ -0,0 +1,72 @@
+package tables
+
+import "time"
+
+// UserDataExport berisi seluruh data pribadi pengguna untuk fitur "unduh data saya".
+// Setiap field ditulis sebagai file JSON terpisah di dalam arsip zip.
+type UserDataExport struct {
+	Profile          ExportedProfile           `json:"profile"`
+	Comments         []ExportedComment         `json:"comments"`
+	Reviews          []ExportedReview          `json:"reviews"`
+	Checkins         []ExportedCheckin         `json:"checkins"`
+	MissionProgress  []ExportedMissionProgress `json:"missionProgress"`
+	CoinTransactions []CoinTransaction         `json:"coinTransactions"`
+	GeneratedAt      time.Time                 `json:"generatedAt"`
+}
+
+// ExportedProfile adalah data profil pengguna tanpa hash password.
+type ExportedProfile struct {
+	UserID          int64      `json:"userId" db:"user_id"`
+	Email           string     `json:"email" db:"email"`
+	FullName        string     `json:"fullName" db:"full_name"`
+	Username        *string    `json:"username,omitempty" db:"username"`
+	PenName         *string    `json:"penName,omitempty" db:"pen_name"`
+	AvatarURL       string     `json:"avatarUrl" db:"avatar_url"`
+	Bio             *string    `json:"bio,omitempty" db:"bio"`
+	LoginWith       string     `json:"loginWith" db:"login_with"`
+	IsEmailVerified bool       `json:"isEmailVerified" db:"is_email_verified"`
+	Phone           *string    `json:"phone,omitempty" db:"phone"`
+	IsPhoneVerified bool       `json:"isPhoneVerified" db:"is_phone_verified"`
+	Instagram       *string    `json:"instagram,omitempty" db:"instagram"`
+	BankID          *int64     `json:"bankId,omitempty" db:"bank_id"`
+	AccountNumber   *string    `json:"accountNumber,omitempty" db:"account_number"`
+	CreateDatetime  time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime  *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+}
+
+// ExportedComment adalah komentar pengguna pada buku, chapter, atau ulasan.
+type ExportedComment struct {
+	CommentID       int64      `json:"commentId" db:"comment_id"`
+	TargetType      string     `json:"targetType" db:"target_type"` // BOOK, CHAPTER, atau REVIEW
+	TargetID        int64      `json:"targetId" db:"target_id"`
+	ParentCommentID *int64     `json:"parentCommentId,omitempty" db:"parent_comment_id"`
+	CommentText     string     `json:"commentText" db:"comment_text"`
+	CreateDatetime  time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime  *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+}
+
+// ExportedReview adalah ulasan dan rating yang diberikan pengguna.
+type ExportedReview struct {
+	ReviewID       int64      `json:"reviewId" db:"review_id"`
+	BookID         int64      `json:"bookId" db:"book_id"`
+	BookTitle      *string    `json:"bookTitle,omitempty" db:"book_title"`
+	Rating         int        `json:"rating" db:"rating"`
+	ReviewText     *string    `json:"reviewText,omitempty" db:"review_text"`
+	CreateDatetime time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+}
+
+// ExportedCheckin adalah riwayat check-in harian pengguna.
+type ExportedCheckin struct {
+	CheckinDate       string `json:"checkinDate" db:"checkin_date"` // Format "YYYY-MM-DD"
+	ConsecutiveStreak int    `json:"consecutiveStreak" db:"consecutive_streak"`
+}
+
+// ExportedMissionProgress adalah progres misi harian pengguna.
+type ExportedMissionProgress struct {
+	ProgressDate      string `json:"progressDate" db:"progress_date"` // Format "YYYY-MM-DD"
+	MissionID         int64  `json:"missionId" db:"mission_id"`
+	MissionTitle      string `json:"missionTitle" db:"mission_title"`
+	CurrentValue      int    `json:"currentValue" db:"current_value"`
+	LastClaimedTierID *int64 `json:"lastClaimedTierId,omitempty" db:"last_claimed_tier_id"`
+}
@@ -0,0 +1,37 @@
+package tables
+
+import "time"
+
+// Status pengajuan menjadi penulis, sesuai ENUM author_application_status.
+const (
+	AuthorApplicationPending  = "PENDING"
+	AuthorApplicationApproved = "APPROVED"
+	AuthorApplicationRejected = "REJECTED"
+)
+
+// AuthorApplication merepresentasikan record dalam tabel author_applications.
+type AuthorApplication struct {
+	ApplicationID    int64      `json:"applicationId" db:"application_id"`
+	UserID           int64      `json:"userId" db:"user_id"`
+	PenName          string     `json:"penName" db:"pen_name"`
+	Phone            string     `json:"phone" db:"phone"`
+	Instagram        string     `json:"instagram" db:"instagram"`
+	BankID           int64      `json:"bankId" db:"bank_id"`
+	AccountNumber    string     `json:"accountNumber" db:"account_number"`
+	Status           string     `json:"status" db:"status"`
+	Reason           string     `json:"reason" db:"reason"`
+	ReviewerID       *int64     `json:"reviewerId,omitempty" db:"reviewer_id"`
+	ReviewedDatetime *time.Time `json:"reviewedDatetime,omitempty" db:"reviewed_datetime"`
+	CreateDatetime   time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime   *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+
+	// Data pemohon, hanya diisi pada daftar pengajuan untuk admin
+	ApplicantName  *string `json:"applicantName,omitempty" db:"applicant_name"`
+	ApplicantEmail *string `json:"applicantEmail,omitempty" db:"applicant_email"`
+}
+
+// PaginatedAuthorApplicationResponse adalah struktur untuk response daftar pengajuan penulis.
+type PaginatedAuthorApplicationResponse struct {
+	Pagination PaginationInfo      `json:"pagination"`
+	Items      []AuthorApplication `json:"applications"`
+}
@@ -0,0 +1,17 @@
+package tables
+
+import "time"
+
+// FollowUser adalah satu baris pada daftar pengikut atau daftar penulis yang diikuti.
+type FollowUser struct {
+	UserID         int64     `json:"userId" db:"user_id"`
+	DisplayName    string    `json:"displayName" db:"display_name"` // Nama pena untuk penulis, username untuk pembaca; nama lengkap tidak pernah ditampilkan
+	AvatarURL      string    `json:"avatarUrl" db:"avatar_url"`
+	FollowDatetime time.Time `json:"followDatetime" db:"follow_datetime"`
+}
+
+// PaginatedFollowResponse adalah daftar pengikut/diikuti beserta info pagination.
+type PaginatedFollowResponse struct {
+	Pagination PaginationInfo `json:"pagination"`
+	Items      []FollowUser   `json:"items"`
+}
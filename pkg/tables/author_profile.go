@@ -0,0 +1,21 @@
+package tables
+
+// PublicAuthor adalah data penulis yang aman ditampilkan di route publik.
+// Jangan pernah mengembalikan User langsung di route publik karena berisi email, telepon, dan rekening bank.
+type PublicAuthor struct {
+	AuthorID  int64   `json:"authorId" db:"author_id"`
+	PenName   *string `json:"penName,omitempty" db:"pen_name"`
+	AvatarURL string  `json:"avatarUrl" db:"avatar_url"`
+	Bio       *string `json:"bio,omitempty" db:"bio"`
+}
+
+// AuthorProfile adalah profil publik penulis beserta statistik agregat dari buku yang sudah terbit.
+type AuthorProfile struct {
+	PublicAuthor
+	TotalBooks    int64   `json:"totalBooks" db:"total_books"`
+	TotalViews    int64   `json:"totalViews" db:"total_views"`
+	AverageRating float64 `json:"averageRating" db:"average_rating"` // Rata-rata semua ulasan di buku penulis
+	TotalReviews  int64   `json:"totalReviews" db:"total_reviews"`
+	FollowerCount int64   `json:"followerCount" db:"follower_count"`
+	IsFollowing   *bool   `json:"isFollowing,omitempty" db:"-"` // Hanya diisi jika request membawa token
+}
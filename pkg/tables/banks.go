@@ -3,12 +3,14 @@ package tables
 import "time"
 
 type Bank struct {
-	BankId            int64      `json:"bankId"`
-	BankName          string     `json:"bankName"`
-	BankCode          *string    `json:"bankCode,omitempty"`
-	Remark            *string    `json:"remark,omitempty"`
-	ActiveDatetime    time.Time  `json:"activeDatetime"`
-	NonActiveDatetime *time.Time `json:"nonActiveDatetime,omitempty"`
-	CreateDatetime    time.Time  `json:"createDatetime"`
-	UpdateDatetime    *time.Time `json:"updateDatetime,omitempty"`
+	BankId                 int64      `json:"bankId"`
+	BankName               string     `json:"bankName"`
+	BankCode               *string    `json:"bankCode,omitempty"`
+	Remark                 *string    `json:"remark,omitempty"`
+	AccountNumberMinLength *int       `json:"accountNumberMinLength,omitempty"`
+	AccountNumberMaxLength *int       `json:"accountNumberMaxLength,omitempty"`
+	ActiveDatetime         time.Time  `json:"activeDatetime"`
+	NonActiveDatetime      *time.Time `json:"nonActiveDatetime,omitempty"`
+	CreateDatetime         time.Time  `json:"createDatetime"`
+	UpdateDatetime         *time.Time `json:"updateDatetime,omitempty"`
 }
@@ -0,0 +1,54 @@
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Nama field yang dicatat di book_revisions.changed_fields.
+const (
+	BookFieldTitle         = "title"
+	BookFieldDescription   = "description"
+	BookFieldCoverImageURL = "coverImageUrl"
+	BookFieldGenres        = "genres"
+)
+
+// BookSnapshot adalah metadata buku pada satu titik waktu, disimpan sebagai JSONB di book_revisions.
+type BookSnapshot struct {
+	Title         string   `json:"title"`
+	Description   *string  `json:"description,omitempty"`
+	CoverImageURL *string  `json:"coverImageUrl,omitempty"`
+	GenreIDs      []int64  `json:"genreIds"`
+	GenreNames    []string `json:"genreNames"` // Nama genre saat revisi dibuat, tetap terbaca walau genre diganti namanya
+}
+
+// Scan membaca kolom JSONB ke dalam BookSnapshot.
+func (s *BookSnapshot) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, s)
+	case string:
+		return json.Unmarshal([]byte(data), s)
+	default:
+		return fmt.Errorf("tipe snapshot buku tidak didukung: %T", src)
+	}
+}
+
+// BookRevision merepresentasikan record dalam tabel book_revisions.
+type BookRevision struct {
+	RevisionID     int64        `json:"revisionId" db:"revision_id"`
+	BookID         int64        `json:"bookId" db:"book_id"`
+	EditorID       *int64       `json:"editorId,omitempty" db:"editor_id"`
+	EditorName     *string      `json:"editorName,omitempty" db:"editor_name"`
+	ChangedFields  []string     `json:"changedFields" db:"changed_fields"`
+	Before         BookSnapshot `json:"before" db:"before_data"`
+	After          BookSnapshot `json:"after" db:"after_data"`
+	CreateDatetime time.Time    `json:"createDatetime" db:"create_datetime"`
+}
+
+// PaginatedBookRevisionResponse adalah struktur untuk response riwayat revisi buku.
+type PaginatedBookRevisionResponse struct {
+	Pagination PaginationInfo `json:"pagination"`
+	Items      []BookRevision `json:"revisions"`
+}
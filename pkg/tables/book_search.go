@@ -0,0 +1,15 @@
+package tables
+
+// GenreFacet adalah jumlah buku per genre pada hasil pencarian.
+type GenreFacet struct {
+	GenreID   int64  `json:"genreId" db:"genre_id"`
+	GenreName string `json:"genreName" db:"genre_name"`
+	Count     int64  `json:"count" db:"book_count"`
+}
+
+// BookSearchResponse adalah struktur untuk response pencarian buku.
+type BookSearchResponse struct {
+	Pagination  PaginationInfo `json:"pagination"`
+	Books       []Book         `json:"books"`
+	GenreFacets []GenreFacet   `json:"genreFacets"`
+}
@@ -0,0 +1,37 @@
+package tables
+
+import "time"
+
+// Status buku, sesuai enum book_status di database.
+const (
+	BookStatusDraft     = "D"
+	BookStatusPublished = "P"
+	BookStatusCompleted = "C"
+	BookStatusOnHold    = "H"
+)
+
+// BookStatusLabels adalah nama status buku untuk pesan yang dibaca pengguna.
+var BookStatusLabels = map[string]string{
+	BookStatusDraft:     "Draft",
+	BookStatusPublished: "Published",
+	BookStatusCompleted: "Completed",
+	BookStatusOnHold:    "On Hold",
+}
+
+// BookStatusChange merepresentasikan record dalam tabel book_status_history.
+type BookStatusChange struct {
+	HistoryID      int64     `json:"historyId" db:"history_id"`
+	BookID         int64     `json:"bookId" db:"book_id"`
+	FromStatus     *string   `json:"fromStatus,omitempty" db:"from_status"`
+	ToStatus       string    `json:"toStatus" db:"to_status"`
+	ActorID        *int64    `json:"actorId,omitempty" db:"actor_id"`
+	ActorName      *string   `json:"actorName,omitempty" db:"actor_name"`
+	Reason         *string   `json:"reason,omitempty" db:"reason"`
+	CreateDatetime time.Time `json:"createDatetime" db:"create_datetime"`
+}
+
+// PaginatedBookStatusHistoryResponse adalah struktur untuk response riwayat status buku.
+type PaginatedBookStatusHistoryResponse struct {
+	Pagination PaginationInfo     `json:"pagination"`
+	Items      []BookStatusChange `json:"history"`
+}
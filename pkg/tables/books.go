@@ -63,10 +63,22 @@ type Review struct {
 type BookDetailResponse struct {
     BookInfo    *Book      `json:"bookInfo"`
     Chapters    []Chapter  `json:"chapters"`
-    Author      *User      `json:"author"`
+    Author      *BookAuthor `json:"author"`
     Reviews     []Review   `json:"reviews"` // Ditambahkan untuk menampung ulasan
 }
 
+// BookAuthor adalah data publik penulis di detail buku. UserID selalu sama dengan AuthorID dan
+// dipertahankan agar klien lama yang membaca key userId tetap berjalan.
+type BookAuthor struct {
+    UserID int64 `json:"userId"`
+    PublicAuthor
+}
+
+// NewBookAuthor membungkus data publik penulis untuk BookDetailResponse.
+func NewBookAuthor(author *PublicAuthor) *BookAuthor {
+    return &BookAuthor{UserID: author.AuthorID, PublicAuthor: *author}
+}
+
 // PaginatedBookResponse adalah struktur untuk response daftar buku yang disertai info pagination.
 type PaginatedBookResponse struct {
     Pagination PaginationInfo `json:"pagination"`
@@ -74,9 +86,15 @@ type PaginatedBookResponse struct {
 }
 
 // PaginationInfo berisi detail tentang pagination.
+// NextCursor/PrevCursor adalah token opaque untuk pagination keyset (parameter ?cursor=), dan
+// Next/Prev adalah link lengkapnya. Saat halaman diambil dengan cursor, CurrentPage bernilai 0.
 type PaginationInfo struct {
-    CurrentPage int   `json:"currentPage"`
-    PageSize    int   `json:"pageSize"`
-    TotalItems  int64 `json:"totalItems"`
-    TotalPages  int   `json:"totalPages"`
+    CurrentPage int    `json:"currentPage"`
+    PageSize    int    `json:"pageSize"`
+    TotalItems  int64  `json:"totalItems"`
+    TotalPages  int    `json:"totalPages"`
+    NextCursor  string `json:"nextCursor,omitempty"`
+    PrevCursor  string `json:"prevCursor,omitempty"`
+    Next        string `json:"next,omitempty"`
+    Prev        string `json:"prev,omitempty"`
 }
@@ -0,0 +1,15 @@
+package tables
+
+import "time"
+
+// LoginAttempt merepresentasikan record dalam tabel login_attempts.
+type LoginAttempt struct {
+	AttemptID      int64     `json:"attemptId" db:"attempt_id"`
+	Identifier     string    `json:"identifier" db:"identifier"`
+	UserID         *int64    `json:"userId,omitempty" db:"user_id"`
+	IPAddress      string    `json:"ipAddress" db:"ip_address"`
+	UserAgent      string    `json:"userAgent" db:"user_agent"`
+	Success        bool      `json:"success" db:"success"`
+	FailureReason  *string   `json:"failureReason,omitempty" db:"failure_reason"`
+	CreateDatetime time.Time `json:"createDatetime" db:"create_datetime"`
+}
@@ -6,6 +6,7 @@ import "time"
 type NotificationResponse struct {
 	NotificationID   int64     `json:"notificationId" db:"notification_id"`
 	NotificationType string    `json:"notificationType" db:"notification_type"`
+	Content          string    `json:"content" db:"content"`
 	IsRead           bool      `json:"isRead" db:"is_read"`
 	CreateDatetime   time.Time `json:"createDatetime" db:"create_datetime"`
 
@@ -0,0 +1,42 @@
+package tables
+
+import "time"
+
+// Asal perubahan rekening pencairan, disimpan di payout_account_changes.source.
+const (
+	PayoutAccountSourceUser              = "USER"
+	PayoutAccountSourceAuthorApplication = "AUTHOR_APPLICATION"
+)
+
+// PayoutAccount adalah rekening pencairan yang sedang dipakai penulis.
+type PayoutAccount struct {
+	BankID            int64      `json:"bankId" db:"bank_id"`
+	BankName          string     `json:"bankName" db:"bank_name"`
+	BankCode          *string    `json:"bankCode,omitempty" db:"bank_code"`
+	AccountNumber     string     `json:"accountNumber" db:"account_number"`
+	EffectiveDatetime *time.Time `json:"effectiveDatetime,omitempty" db:"effective_datetime"` // Pencairan ke rekening ini baru dikirim setelah waktu ini
+	IsOnHold          bool       `json:"isOnHold" db:"-"`                                     // true selama masa tunggu setelah perubahan
+}
+
+// PayoutAccountChange merepresentasikan record dalam tabel payout_account_changes.
+type PayoutAccountChange struct {
+	ChangeID          int64     `json:"changeId" db:"change_id"`
+	UserID            int64     `json:"-" db:"user_id"`
+	OldBankID         *int64    `json:"oldBankId,omitempty" db:"old_bank_id"`
+	OldBankName       *string   `json:"oldBankName,omitempty" db:"old_bank_name"`
+	OldAccountNumber  *string   `json:"oldAccountNumber,omitempty" db:"old_account_number"`
+	NewBankID         int64     `json:"newBankId" db:"new_bank_id"`
+	NewBankName       string    `json:"newBankName" db:"new_bank_name"`
+	NewAccountNumber  string    `json:"newAccountNumber" db:"new_account_number"`
+	Source            string    `json:"source" db:"source"`
+	IPAddress         string    `json:"ipAddress" db:"ip_address"`
+	UserAgent         string    `json:"userAgent" db:"user_agent"`
+	EffectiveDatetime time.Time `json:"effectiveDatetime" db:"effective_datetime"`
+	CreateDatetime    time.Time `json:"createDatetime" db:"create_datetime"`
+}
+
+// PaginatedPayoutAccountChangeResponse adalah struktur untuk response riwayat perubahan rekening pencairan.
+type PaginatedPayoutAccountChangeResponse struct {
+	Pagination PaginationInfo        `json:"pagination"`
+	Items      []PayoutAccountChange `json:"changes"`
+}
@@ -0,0 +1,16 @@
+package tables
+
+import "time"
+
+// PhoneVerification merepresentasikan record dalam tabel phone_verifications.
+type PhoneVerification struct {
+	VerificationID   int64      `json:"-" db:"verification_id"`
+	UserID           int64      `json:"-" db:"user_id"`
+	Phone            string     `json:"phone" db:"phone"`
+	Channel          string     `json:"channel" db:"channel"`
+	CodeHash         string     `json:"-" db:"code_hash"`
+	Attempts         int        `json:"-" db:"attempts"`
+	ExpiresDatetime  time.Time  `json:"expiresDatetime" db:"expires_datetime"`
+	VerifiedDatetime *time.Time `json:"verifiedDatetime,omitempty" db:"verified_datetime"`
+	CreateDatetime   time.Time  `json:"createDatetime" db:"create_datetime"`
+}
@@ -0,0 +1,32 @@
+package tables
+
+import "time"
+
+// UserSession merepresentasikan record dalam tabel user_sessions.
+type UserSession struct {
+	SessionID            int64      `json:"sessionId" db:"session_id"`
+	UserID               int64      `json:"-" db:"user_id"`
+	RefreshTokenHash     string     `json:"-" db:"refresh_token_hash"`
+	PreviousTokenHash    *string    `json:"-" db:"previous_token_hash"`
+	DeviceName           string     `json:"deviceName" db:"device_name"`
+	UserAgent            string     `json:"userAgent" db:"user_agent"`
+	IPAddress            string     `json:"ipAddress" db:"ip_address"`
+	ExpiresDatetime      time.Time  `json:"expiresDatetime" db:"expires_datetime"`
+	RevokedDatetime      *time.Time `json:"revokedDatetime,omitempty" db:"revoked_datetime"`
+	SecondFactorDatetime *time.Time `json:"secondFactorDatetime,omitempty" db:"second_factor_datetime"`
+	LastSeenDatetime     time.Time  `json:"lastSeenDatetime" db:"last_seen_datetime"`
+	CreateDatetime       time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime       *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+}
+
+// ActiveSession adalah sesi yang ditampilkan di daftar perangkat pengguna.
+type ActiveSession struct {
+	SessionID        int64     `json:"sessionId" db:"session_id"`
+	DeviceName       string    `json:"deviceName" db:"device_name"`
+	UserAgent        string    `json:"userAgent" db:"user_agent"`
+	IPAddress        string    `json:"ipAddress" db:"ip_address"`
+	CreateDatetime   time.Time `json:"createDatetime" db:"create_datetime"`
+	LastSeenDatetime time.Time `json:"lastSeenDatetime" db:"last_seen_datetime"`
+	ExpiresDatetime  time.Time `json:"expiresDatetime" db:"expires_datetime"`
+	IsCurrent        bool      `json:"isCurrent" db:"-"`
+}
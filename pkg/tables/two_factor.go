@@ -0,0 +1,18 @@
+package tables
+
+import "time"
+
+// UserTOTP merepresentasikan record dalam tabel user_totp.
+type UserTOTP struct {
+	UserID            int64      `json:"-" db:"user_id"`
+	Secret            string     `json:"-" db:"secret"`
+	ConfirmedDatetime *time.Time `json:"confirmedDatetime,omitempty" db:"confirmed_datetime"`
+	LastUsedStep      int64      `json:"-" db:"last_used_step"`
+	CreateDatetime    time.Time  `json:"createDatetime" db:"create_datetime"`
+	UpdateDatetime    *time.Time `json:"updateDatetime,omitempty" db:"update_datetime"`
+}
+
+// IsConfirmed menandakan 2FA sudah aktif, bukan sekadar pendaftaran yang belum dikonfirmasi.
+func (t *UserTOTP) IsConfirmed() bool {
+	return t != nil && t.ConfirmedDatetime != nil
+}
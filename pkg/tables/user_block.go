@@ -0,0 +1,25 @@
+package tables
+
+import "time"
+
+const (
+	// BlockTypeMute menyembunyikan komentar dan notifikasi dari pengguna yang dibisukan.
+	BlockTypeMute = "MUTE"
+	// BlockTypeBlock menambahkan larangan membalas komentar di atas efek BlockTypeMute.
+	BlockTypeBlock = "BLOCK"
+)
+
+// BlockedUser adalah satu baris pada daftar pengguna yang diblokir atau dibisukan.
+type BlockedUser struct {
+	UserID         int64     `json:"userId" db:"user_id"`
+	DisplayName    string    `json:"displayName" db:"display_name"`
+	AvatarURL      string    `json:"avatarUrl" db:"avatar_url"`
+	BlockType      string    `json:"blockType" db:"block_type"`
+	CreateDatetime time.Time `json:"createDatetime" db:"create_datetime"`
+}
+
+// PaginatedBlockedUserResponse adalah daftar pengguna yang diblokir/dibisukan beserta info pagination.
+type PaginatedBlockedUserResponse struct {
+	Pagination PaginationInfo `json:"pagination"`
+	Items      []BlockedUser  `json:"items"`
+}
@@ -0,0 +1,28 @@
+package tables
+
+import "time"
+
+// Role pengguna, sesuai ENUM user_role.
+const (
+	RoleReader    = "reader"
+	RoleAuthor    = "author"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// UserRole merepresentasikan record dalam tabel user_roles.
+type UserRole struct {
+	UserID         int64     `json:"userId" db:"user_id"`
+	Role           string    `json:"role" db:"role"`
+	GrantedBy      *int64    `json:"grantedBy,omitempty" db:"granted_by"`
+	CreateDatetime time.Time `json:"createDatetime" db:"create_datetime"`
+}
+
+// IsValidRole memeriksa apakah role dikenal sistem.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleReader, RoleAuthor, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
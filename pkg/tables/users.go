@@ -14,10 +14,13 @@ type User struct {
 	LoginWith       string     `json:"loginWith"`
 	IsEmailVerified bool       `json:"isEmailVerified"`
 	Phone           *string    `json:"phone,omitempty"`
+	IsPhoneVerified bool       `json:"isPhoneVerified"`
 	Instagram       *string    `json:"instagram,omitempty"`
 	BankId          *int64     `json:"bankId,omitempty"`
 	AccountNumber   *string    `json:"accountNumber,omitempty"`
 	FlgAuthor       string     `json:"flgAuthor"`
+	Bio             *string    `json:"bio,omitempty"`
+	DeletedDatetime *time.Time `json:"deletedDatetime,omitempty"`
 	CreateDatetime  time.Time  `json:"createDatetime"`
 	UpdateDatetime  *time.Time `json:"updateDatetime,omitempty"`
 }
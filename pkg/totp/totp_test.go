@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret SHA1 dari lampiran B RFC 6238 ("12345678901234567890") dalam base32.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// Nilai di RFC berupa 8 digit; kode 6 digit adalah 6 digit terakhirnya.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	step := Step(time.Unix(59, 0))
+	upper, _ := Code(rfcSecret, step)
+	lower, err := Code(" "+strings.ToLower(rfcSecret)+" ", step)
+	if err != nil || lower != upper {
+		t.Fatalf("Code with lowercase secret = %q, %v; want %q", lower, err, upper)
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("Code with invalid secret returned no error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		lastUsed int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), 1, 0, current, true},
+		{"code with spaces", code(current)[:3] + " " + code(current)[3:], 1, 0, current, true},
+		{"previous step within skew", code(current - 1), 1, 0, current - 1, true},
+		{"next step within skew", code(current + 1), 1, 0, current + 1, true},
+		{"outside skew", code(current - 2), 1, 0, 0, false},
+		{"no skew", code(current - 1), 0, 0, 0, false},
+		{"replayed step", code(current), 1, current, 0, false},
+		{"older than last used step", code(current - 1), 1, current - 1, 0, false},
+		{"wrong length", "12345", 1, 0, 0, false},
+		{"wrong code", "000000", 1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew, tt.lastUsed)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q bukan base32 yang valid: %v", secret, err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("panjang secret = %d byte, want %d", len(key), secretSize)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Error("GenerateSecret menghasilkan secret yang sama dua kali")
+	}
+}